@@ -14,9 +14,10 @@ var timeout = 10 * time.Minute
 
 // Client represents the Bothub chat API client
 type Client struct {
-	httpClient *http.Client
-	token      string
-	baseURL    string
+	httpClient    *http.Client
+	token         string
+	baseURL       string
+	embeddingsURL string
 }
 
 // NewClient creates a new Bothub client instance
@@ -40,7 +41,8 @@ func NewClient(di *do.Injector) (*Client, error) {
 				ExpectContinueTimeout: timeout,
 			},
 		},
-		token:   cfg.Bothub.Token,
-		baseURL: "https://bothub.chat/api/v2/openai/v1/chat/completions",
+		token:         cfg.Bothub.Token,
+		baseURL:       "https://bothub.chat/api/v2/openai/v1/chat/completions",
+		embeddingsURL: "https://bothub.chat/api/v2/openai/v1/embeddings",
 	}, nil
 }
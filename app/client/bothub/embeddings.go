@@ -0,0 +1,85 @@
+package bothub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var ModelTextEmbedding3Small = "text-embedding-3-small"
+
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *apiError `json:"error,omitempty"`
+}
+
+// Embed returns an embedding vector for every input text, in the same order
+func (c *Client) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	if model == "" {
+		model = ModelTextEmbedding3Small
+	}
+
+	jsonData, err := json.Marshal(embeddingsRequest{
+		Model: model,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.embeddingsURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var apiResp embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if apiResp.Error != nil {
+		return nil, fmt.Errorf("API error: %s (type: %s, code: %d)",
+			apiResp.Error.Message, apiResp.Error.Type, apiResp.Error.Code)
+	}
+
+	if len(apiResp.Data) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(apiResp.Data), len(texts))
+	}
+
+	result := make([][]float32, len(texts))
+
+	for _, item := range apiResp.Data {
+		if item.Index < 0 || item.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d is out of range", item.Index)
+		}
+
+		result[item.Index] = item.Embedding
+	}
+
+	return result, nil
+}
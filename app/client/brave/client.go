@@ -0,0 +1,149 @@
+package brave
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"frank/pkg/config"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/samber/do"
+)
+
+const webSearchURL = "https://api.search.brave.com/res/v1/web/search"
+
+var timeout = 30 * time.Second
+
+// limits of the API
+var maxResultCount = 20
+var maxOffset = 9
+
+var safeSearchLevels = map[string]string{
+	"none":     "off",
+	"moderate": "moderate",
+	"strict":   "strict",
+}
+
+// snippets highlight matched words with <strong>
+var tagRegexp = regexp.MustCompile(`</?[a-zA-Z]+>`)
+
+func plainText(text string) string {
+	return html.UnescapeString(tagRegexp.ReplaceAllString(text, ""))
+}
+
+type Client struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+func NewClient(di *do.Injector) (*Client, error) {
+	cfg := do.MustInvoke[*config.Config](di)
+
+	if cfg.Search.Brave.Token == "" {
+		return nil, fmt.Errorf("brave token is required")
+	}
+
+	return &Client{
+		token:   cfg.Search.Brave.Token,
+		baseURL: webSearchURL,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+type searchResponse struct {
+	Web struct {
+		Results []struct {
+			Title         string   `json:"title"`
+			URL           string   `json:"url"`
+			Description   string   `json:"description"`
+			ExtraSnippets []string `json:"extra_snippets"`
+			MetaURL       struct {
+				Hostname string `json:"hostname"`
+			} `json:"meta_url"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+func (c *Client) WebSearch(ctx context.Context, query string, opts dto.WebSearchOptions) ([]dto.WebSearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+
+	if opts.Count > 0 {
+		params.Set("count", strconv.Itoa(min(opts.Count, maxResultCount)))
+	}
+
+	// the offset is counted in pages
+	if opts.Page > 1 {
+		params.Set("offset", strconv.Itoa(min(opts.Page-1, maxOffset)))
+	}
+
+	if opts.L10n != "" {
+		params.Set("search_lang", opts.L10n)
+	}
+
+	if opts.FamilyMode != "" {
+		level, ok := safeSearchLevels[opts.FamilyMode]
+		if !ok {
+			return nil, fmt.Errorf("unknown family mode %q", opts.FamilyMode)
+		}
+
+		params.Set("safesearch", level)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", c.token)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	bytez, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got response with status %d: %s", res.StatusCode, string(bytez))
+	}
+
+	var searchRes searchResponse
+	if err = json.Unmarshal(bytez, &searchRes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	results := make([]dto.WebSearchResult, 0, len(searchRes.Web.Results))
+
+	for _, result := range searchRes.Web.Results {
+		item := dto.WebSearchResult{
+			Title:   plainText(result.Title),
+			URL:     result.URL,
+			Domain:  result.MetaURL.Hostname,
+			Snippet: plainText(result.Description),
+		}
+
+		for _, snippet := range result.ExtraSnippets {
+			item.Passages = append(item.Passages, plainText(snippet))
+		}
+
+		results = append(results, item)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,111 @@
+package brave
+
+import (
+	"context"
+	"frank/app/dto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WebSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("X-Subscription-Token"))
+		assert.Equal(t, "golang", r.URL.Query().Get("q"))
+		assert.Equal(t, "20", r.URL.Query().Get("count"))
+		assert.Equal(t, "1", r.URL.Query().Get("offset"))
+		assert.Equal(t, "ru", r.URL.Query().Get("search_lang"))
+		assert.Equal(t, "strict", r.URL.Query().Get("safesearch"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"type": "search", "web": {"results": [
+			{
+				"title": "The <strong>Go</strong> Programming Language",
+				"url": "https://go.dev/",
+				"description": "Build simple &amp; <strong>secure</strong> systems",
+				"extra_snippets": ["Download <strong>Go</strong>", "Tour of Go"],
+				"meta_url": {"hostname": "go.dev"}
+			},
+			{
+				"title": "Go - Wikipedia",
+				"url": "https://en.wikipedia.org/wiki/Go",
+				"description": "",
+				"meta_url": {"hostname": "en.wikipedia.org"}
+			}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{token: "test-token", baseURL: server.URL, client: server.Client()}
+
+	results, err := client.WebSearch(context.Background(), "golang", dto.WebSearchOptions{
+		Page:       2,
+		Count:      50,
+		L10n:       "ru",
+		FamilyMode: "strict",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []dto.WebSearchResult{
+		{
+			Title:    "The Go Programming Language",
+			URL:      "https://go.dev/",
+			Domain:   "go.dev",
+			Snippet:  "Build simple & secure systems",
+			Passages: []string{"Download Go", "Tour of Go"},
+		},
+		{
+			Title:  "Go - Wikipedia",
+			URL:    "https://en.wikipedia.org/wiki/Go",
+			Domain: "en.wikipedia.org",
+		},
+	}, results)
+}
+
+func TestClient_WebSearch_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		opts   dto.WebSearchOptions
+		errMsg string
+	}{
+		{
+			name:   "error status",
+			status: http.StatusTooManyRequests,
+			body:   `{"type": "ErrorResponse", "error": {"code": "RATE_LIMITED"}}`,
+			errMsg: "got response with status 429",
+		},
+		{
+			name:   "malformed response",
+			status: http.StatusOK,
+			body:   `{"web": `,
+			errMsg: "failed to unmarshal response",
+		},
+		{
+			name:   "unknown family mode",
+			status: http.StatusOK,
+			opts:   dto.WebSearchOptions{FamilyMode: "kids"},
+			errMsg: `unknown family mode "kids"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := &Client{token: "test-token", baseURL: server.URL, client: server.Client()}
+
+			_, err := client.WebSearch(context.Background(), "golang", tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package searxng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"frank/pkg/config"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samber/do"
+)
+
+var timeout = 30 * time.Second
+
+var defaultResultCount = 10
+
+var safeSearchLevels = map[string]string{
+	"none":     "0",
+	"moderate": "1",
+	"strict":   "2",
+}
+
+// Client queries the JSON API of a self-hosted SearXNG instance, which must have the json format enabled
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewClient(di *do.Injector) (*Client, error) {
+	cfg := do.MustInvoke[*config.Config](di)
+
+	if cfg.Search.SearXNG.URL == "" {
+		return nil, fmt.Errorf("searxng url is required")
+	}
+
+	return &Client{
+		baseURL: strings.TrimSuffix(cfg.Search.SearXNG.URL, "/"),
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+type searchResponse struct {
+	Results []struct {
+		URL     string `json:"url"`
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+func (c *Client) WebSearch(ctx context.Context, query string, opts dto.WebSearchOptions) ([]dto.WebSearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "json")
+
+	if opts.Page > 1 {
+		params.Set("pageno", strconv.Itoa(opts.Page))
+	}
+
+	if opts.L10n != "" {
+		params.Set("language", opts.L10n)
+	}
+
+	if opts.FamilyMode != "" {
+		level, ok := safeSearchLevels[opts.FamilyMode]
+		if !ok {
+			return nil, fmt.Errorf("unknown family mode %q", opts.FamilyMode)
+		}
+
+		params.Set("safesearch", level)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	bytez, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got response with status %d: %s", res.StatusCode, string(bytez))
+	}
+
+	var searchRes searchResponse
+	if err = json.Unmarshal(bytez, &searchRes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	// SearXNG has no page size parameter
+	count := opts.Count
+	if count <= 0 {
+		count = defaultResultCount
+	}
+
+	results := make([]dto.WebSearchResult, 0, min(count, len(searchRes.Results)))
+
+	for _, result := range searchRes.Results {
+		if len(results) == count {
+			break
+		}
+
+		var domain string
+		if parsed, err := url.Parse(result.URL); err == nil {
+			domain = parsed.Hostname()
+		}
+
+		results = append(results, dto.WebSearchResult{
+			Title:   result.Title,
+			URL:     result.URL,
+			Domain:  domain,
+			Snippet: result.Content,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,41 @@
+package searxng
+
+import (
+	"context"
+	"frank/app/dto"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WebSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "golang", r.URL.Query().Get("q"))
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		assert.Equal(t, "2", r.URL.Query().Get("pageno"))
+		assert.Equal(t, "2", r.URL.Query().Get("safesearch"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results": [
+			{"url": "https://go.dev/", "title": "The Go Programming Language", "content": "Go is an open source language"},
+			{"url": "https://en.wikipedia.org/wiki/Go", "title": "Go - Wikipedia", "content": ""}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{baseURL: server.URL, client: server.Client()}
+
+	results, err := client.WebSearch(context.Background(), "golang", dto.WebSearchOptions{Page: 2, FamilyMode: "strict", Count: 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, []dto.WebSearchResult{{
+		Title:   "The Go Programming Language",
+		URL:     "https://go.dev/",
+		Domain:  "go.dev",
+		Snippet: "Go is an open source language",
+	}}, results)
+}
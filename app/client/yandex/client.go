@@ -1,25 +1,82 @@
 package yandex
 
 import (
+	"context"
+	"crypto/rsa"
+	"fmt"
 	"frank/pkg/config"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/samber/do"
+	ycsdk "github.com/yandex-cloud/go-sdk"
+	"golang.org/x/sync/singleflight"
 )
 
+var _ do.Shutdownable = (*Client)(nil)
+
 type Client struct {
-	cfg    *config.Config
-	client *http.Client
+	cfg        *config.Config
+	client     *http.Client
+	sdk        *ycsdk.SDK
+	privateKey *rsa.PrivateKey
+
+	tokenGroup     singleflight.Group
+	token          string
+	tokenExpiresAt time.Time
+	tokenMu        sync.RWMutex
+
+	// totals since start, logged along with every refresh
+	tokenCacheHits     atomic.Int64
+	tokenRefreshes     atomic.Int64
+	tokenRefreshErrors atomic.Int64
 }
 
 func NewClient(di *do.Injector) (*Client, error) {
 	cfg := do.MustInvoke[*config.Config](di)
 
-	return &Client{
+	client := &Client{
 		cfg: cfg,
 		client: &http.Client{
 			Timeout: time.Second * 30,
 		},
-	}, nil
+	}
+
+	authKey, err := client.readPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	if client.privateKey, err = client.loadPrivateKey(); err != nil {
+		return nil, fmt.Errorf("failed to load private key: %w", err)
+	}
+
+	credentials, err := ycsdk.ServiceAccountKey(authKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not get service account key: %w", err)
+	}
+
+	client.sdk, err = ycsdk.Build(do.MustInvoke[context.Context](di), ycsdk.Config{
+		Credentials: credentials,
+		// connect lazily, startup must not depend on Yandex Cloud availability
+		DialContextTimeout: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not build sdk: %w", err)
+	}
+
+	return client, nil
+}
+
+func (c *Client) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := c.sdk.Shutdown(ctx); err != nil {
+		return fmt.Errorf("sdk.Shutdown: %w", err)
+	}
+
+	return nil
 }
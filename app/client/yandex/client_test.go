@@ -2,7 +2,10 @@ package yandex
 
 import (
 	"context"
+	"frank/app/dto"
 	"frank/pkg/config"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/samber/do"
@@ -20,6 +23,7 @@ func TestClient_WebSearch_Integration(t *testing.T) {
 
 	// Setup dependency injection
 	di := do.New()
+	do.ProvideValue(di, context.Background())
 	do.ProvideValue(di, cfg)
 
 	// Create client
@@ -28,9 +32,11 @@ func TestClient_WebSearch_Integration(t *testing.T) {
 	require.NotNil(t, client)
 
 	t.Run("should return google", func(t *testing.T) {
-		result, err := client.WebSearch(context.Background(), "current weather saint petersburg russia")
+		results, err := client.WebSearch(context.Background(), "current weather saint petersburg russia", dto.WebSearchOptions{})
 		require.NoError(t, err)
-		require.NotNil(t, result)
-		require.Contains(t, result, "google.com")
+		require.NotEmpty(t, results)
+		require.True(t, slices.ContainsFunc(results, func(result dto.WebSearchResult) bool {
+			return strings.Contains(result.Domain, "google.com")
+		}))
 	})
 }
@@ -0,0 +1,98 @@
+package yandex
+
+import (
+	"encoding/xml"
+	"fmt"
+	"frank/app/dto"
+	"strings"
+)
+
+// "no results found" is reported as an error by the API
+const noResultsErrorCode = "15"
+
+type searchXML struct {
+	Response struct {
+		Error *struct {
+			Code    string `xml:"code,attr"`
+			Message string `xml:",chardata"`
+		} `xml:"error"`
+		Groups []struct {
+			Docs []docXML `xml:"doc"`
+		} `xml:"results>grouping>group"`
+	} `xml:"response"`
+}
+
+type docXML struct {
+	URL      string            `xml:"url"`
+	Domain   string            `xml:"domain"`
+	Title    highlightedText   `xml:"title"`
+	Headline highlightedText   `xml:"headline"`
+	Passages []highlightedText `xml:"passages>passage"`
+}
+
+// highlightedText collects the text of an element, dropping the <hlword> markup of matched words
+type highlightedText string
+
+func (h *highlightedText) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	var builder strings.Builder
+
+	for depth := 0; ; {
+		token, err := d.Token()
+		if err != nil {
+			return fmt.Errorf("d.Token: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			builder.Write(t)
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				*h = highlightedText(strings.Join(strings.Fields(builder.String()), " "))
+				return nil
+			}
+
+			depth--
+		}
+	}
+}
+
+// parseResults extracts the found documents from the XML response
+func parseResults(data []byte) ([]dto.WebSearchResult, error) {
+	var parsed searchXML
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("xml.Unmarshal: %w", err)
+	}
+
+	if parsed.Response.Error != nil {
+		if parsed.Response.Error.Code == noResultsErrorCode {
+			return []dto.WebSearchResult{}, nil
+		}
+
+		return nil, fmt.Errorf("search error %s: %s", parsed.Response.Error.Code, strings.TrimSpace(parsed.Response.Error.Message))
+	}
+
+	results := make([]dto.WebSearchResult, 0, len(parsed.Response.Groups))
+
+	for _, group := range parsed.Response.Groups {
+		for _, doc := range group.Docs {
+			result := dto.WebSearchResult{
+				Title:   string(doc.Title),
+				URL:     strings.TrimSpace(doc.URL),
+				Domain:  strings.TrimSpace(doc.Domain),
+				Snippet: string(doc.Headline),
+			}
+
+			for _, passage := range doc.Passages {
+				if passage != "" {
+					result.Passages = append(result.Passages, string(passage))
+				}
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,98 @@
+package yandex
+
+import (
+	"frank/app/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSearchXML = `<?xml version="1.0" encoding="utf-8"?>
+<yandexsearch version="1.0">
+<request><query>go memory model</query></request>
+<response date="20250101T120000">
+<reqid>1234</reqid>
+<found priority="all">1000</found>
+<results>
+<grouping attr="" mode="flat" groups-on-page="10" docs-in-group="1" curcateg="-1">
+<page first="1" last="2">0</page>
+<group>
+<categ attr="" name="go.dev"/>
+<doccount>1</doccount>
+<doc id="A1">
+<url>https://go.dev/ref/mem</url>
+<domain>go.dev</domain>
+<title>The <hlword>Go</hlword> <hlword>Memory</hlword> Model</title>
+<headline>Advice for programmers</headline>
+<passages>
+<passage>The <hlword>Go</hlword> memory model specifies the conditions...</passage>
+<passage>Don't be clever.</passage>
+</passages>
+<mime-type>text/html</mime-type>
+</doc>
+</group>
+<group>
+<categ attr="" name="example.com"/>
+<doc id="B2">
+<url>https://example.com/go</url>
+<domain>example.com</domain>
+<title>Example &amp; more</title>
+</doc>
+</group>
+</grouping>
+</results>
+</response>
+</yandexsearch>`
+
+func TestParseResults(t *testing.T) {
+	results, err := parseResults([]byte(testSearchXML))
+	require.NoError(t, err)
+
+	assert.Equal(t, []dto.WebSearchResult{
+		{
+			Title:   "The Go Memory Model",
+			URL:     "https://go.dev/ref/mem",
+			Domain:  "go.dev",
+			Snippet: "Advice for programmers",
+			Passages: []string{
+				"The Go memory model specifies the conditions...",
+				"Don't be clever.",
+			},
+		},
+		{
+			Title:  "Example & more",
+			URL:    "https://example.com/go",
+			Domain: "example.com",
+		},
+	}, results)
+}
+
+func TestParseResults_Errors(t *testing.T) {
+	results, err := parseResults([]byte(`<yandexsearch><response><error code="15">Sorry, there are no results</error></response></yandexsearch>`))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = parseResults([]byte(`<yandexsearch><response><error code="32">Limit exceeded</error></response></yandexsearch>`))
+	assert.ErrorContains(t, err, "search error 32: Limit exceeded")
+}
+
+func TestNewWebSearchRequest(t *testing.T) {
+	req, err := newWebSearchRequest("query", dto.WebSearchOptions{
+		Page:       3,
+		Region:     "213",
+		L10n:       "en",
+		FamilyMode: "strict",
+		Count:      500,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2", req.Query.Page)
+	assert.Equal(t, "213", req.Region)
+	assert.Equal(t, "LOCALIZATION_EN", req.L10n)
+	assert.Equal(t, "FAMILY_MODE_STRICT", req.Query.FamilyMode)
+	assert.Equal(t, "100", req.GroupSpec.GroupsOnPage)
+
+	_, err = newWebSearchRequest("query", dto.WebSearchOptions{L10n: "de"})
+	assert.Error(t, err)
+}
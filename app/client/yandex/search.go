@@ -6,8 +6,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"frank/app/dto"
 	"io"
 	"net/http"
+	"strconv"
 )
 
 const webSearchURL = "https://searchapi.api.cloud.yandex.net/v2/web/search"
@@ -47,25 +49,40 @@ type WebSearchResponse struct {
 	RawData string `json:"rawData"`
 }
 
-func (c *Client) WebSearch(ctx context.Context, query string) (string, error) {
-	req := &WebSearchRequest{
-		Query: WebSearchQuery{
-			SearchType: "SEARCH_TYPE_COM",
-			QueryText:  query,
-			FamilyMode: "FAMILY_MODE_NONE",
-		},
-		ResponseFormat: "FORMAT_XML",
-		FolderId:       c.cfg.Yandex.FolderID,
+var defaultResultCount = 10
+var maxResultCount = 100
+
+var localizations = map[string]string{
+	"ru": "LOCALIZATION_RU",
+	"en": "LOCALIZATION_EN",
+	"uk": "LOCALIZATION_UK",
+	"be": "LOCALIZATION_BE",
+	"kk": "LOCALIZATION_KK",
+	"tr": "LOCALIZATION_TR",
+}
+
+var familyModes = map[string]string{
+	"none":     "FAMILY_MODE_NONE",
+	"moderate": "FAMILY_MODE_MODERATE",
+	"strict":   "FAMILY_MODE_STRICT",
+}
+
+func (c *Client) WebSearch(ctx context.Context, query string, opts dto.WebSearchOptions) ([]dto.WebSearchResult, error) {
+	req, err := newWebSearchRequest(query, opts)
+	if err != nil {
+		return nil, err
 	}
 
+	req.FolderId = c.cfg.Yandex.FolderID
+
 	jsonBytes, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	iamToken, err := c.getIAMToken(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get IAM token: %w", err)
+		return nil, fmt.Errorf("failed to get IAM token: %w", err)
 	}
 
 	httpReq, err := http.NewRequestWithContext(
@@ -75,7 +92,7 @@ func (c *Client) WebSearch(ctx context.Context, query string) (string, error) {
 		bytes.NewReader(jsonBytes),
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -84,28 +101,77 @@ func (c *Client) WebSearch(ctx context.Context, query string) (string, error) {
 
 	res, err := c.client.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer res.Body.Close()
 
 	bytez, err := io.ReadAll(res.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("got response with status %d: %s", res.StatusCode, string(bytez))
+		return nil, fmt.Errorf("got response with status %d: %s", res.StatusCode, string(bytez))
 	}
 
 	var webSearchRes WebSearchResponse
 	if err = json.Unmarshal(bytez, &webSearchRes); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	decodedBytes, err := base64.StdEncoding.DecodeString(webSearchRes.RawData)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	results, err := parseResults(decodedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return results, nil
+}
+
+func newWebSearchRequest(query string, opts dto.WebSearchOptions) (*WebSearchRequest, error) {
+	familyMode := "FAMILY_MODE_NONE"
+	if opts.FamilyMode != "" {
+		var ok bool
+		if familyMode, ok = familyModes[opts.FamilyMode]; !ok {
+			return nil, fmt.Errorf("unknown family mode %q", opts.FamilyMode)
+		}
+	}
+
+	req := &WebSearchRequest{
+		Query: WebSearchQuery{
+			SearchType: "SEARCH_TYPE_COM",
+			QueryText:  query,
+			FamilyMode: familyMode,
+		},
+		Region:         opts.Region,
+		ResponseFormat: "FORMAT_XML",
+	}
+
+	if opts.Page > 1 {
+		req.Query.Page = strconv.Itoa(opts.Page - 1)
+	}
+
+	if opts.L10n != "" {
+		var ok bool
+		if req.L10n, ok = localizations[opts.L10n]; !ok {
+			return nil, fmt.Errorf("unknown l10n %q", opts.L10n)
+		}
+	}
+
+	count := opts.Count
+	if count <= 0 {
+		count = defaultResultCount
+	}
+
+	req.GroupSpec = &WebSearchGroupSpec{
+		GroupMode:    "GROUP_MODE_FLAT",
+		GroupsOnPage: strconv.Itoa(min(count, maxResultCount)),
+		DocsInGroup:  "1",
 	}
 
-	return string(decodedBytes), nil
+	return req, nil
 }
@@ -5,14 +5,18 @@ import (
 	"crypto/rsa"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/yandex-cloud/go-genproto/yandex/cloud/iam/v1"
-	ycsdk "github.com/yandex-cloud/go-sdk"
 	"github.com/yandex-cloud/go-sdk/iamkey"
 )
 
+// tokens live for 12 hours, refreshing a bit earlier avoids using one that expires mid-request
+var tokenRefreshMargin = 5 * time.Minute
+var tokenRefreshTimeout = 30 * time.Second
+
 func (c *Client) signedJWTToken() (string, error) {
 	claims := jwt.RegisteredClaims{
 		Issuer:    c.cfg.Yandex.ServiceAccountID,
@@ -24,12 +28,7 @@ func (c *Client) signedJWTToken() (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodPS256, claims)
 	token.Header["kid"] = c.cfg.Yandex.KeyID
 
-	privateKey, err := c.loadPrivateKey()
-	if err != nil {
-		return "", fmt.Errorf("failed to load private key: %w", err)
-	}
-
-	signed, err := token.SignedString(privateKey)
+	signed, err := token.SignedString(c.privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -60,37 +59,82 @@ func (c *Client) readPrivateKey() (*iamkey.Key, error) {
 	return keyData, nil
 }
 
+// getIAMToken returns the cached token, refreshing it shortly before expiry
 func (c *Client) getIAMToken(ctx context.Context) (string, error) {
-	authKey, err := c.readPrivateKey()
-	if err != nil {
-		return "", fmt.Errorf("failed to read private key: %w", err)
+	c.tokenMu.RLock()
+	token, expiresAt := c.token, c.tokenExpiresAt
+	c.tokenMu.RUnlock()
+
+	if token != "" && time.Now().Before(expiresAt.Add(-tokenRefreshMargin)) {
+		c.tokenCacheHits.Add(1)
+		return token, nil
 	}
 
-	credentials, err := ycsdk.ServiceAccountKey(authKey)
+	// concurrent searches share a single refresh, which must not be cancelled together with the first caller
+	result, err, _ := c.tokenGroup.Do("iam", func() (any, error) {
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenRefreshTimeout)
+		defer cancel()
+
+		return c.refreshIAMToken(refreshCtx)
+	})
 	if err != nil {
-		return "", fmt.Errorf("could not get service account key: %w", err)
+		return "", err
 	}
 
-	sdk, err := ycsdk.Build(ctx, ycsdk.Config{
-		Credentials: credentials,
-	})
+	return result.(string), nil
+}
+
+func (c *Client) refreshIAMToken(ctx context.Context) (string, error) {
+	c.tokenRefreshes.Add(1)
+
+	token, expiresAt, err := c.createIAMToken(ctx)
 	if err != nil {
-		return "", fmt.Errorf("could not build sdk: %w", err)
+		c.tokenRefreshErrors.Add(1)
+
+		slog.WarnContext(ctx, "Failed to refresh Yandex IAM token",
+			slog.Any("error", err),
+			c.tokenMetrics(),
+		)
+
+		return "", err
 	}
 
+	c.tokenMu.Lock()
+	c.token = token
+	c.tokenExpiresAt = expiresAt
+	c.tokenMu.Unlock()
+
+	slog.InfoContext(ctx, "Yandex IAM token refreshed",
+		slog.Time("expires_at", expiresAt),
+		c.tokenMetrics(),
+	)
+
+	return token, nil
+}
+
+func (c *Client) createIAMToken(ctx context.Context) (string, time.Time, error) {
 	jwtToken, err := c.signedJWTToken()
 	if err != nil {
-		return "", fmt.Errorf("could not get token: %w", err)
+		return "", time.Time{}, fmt.Errorf("could not get token: %w", err)
 	}
 
 	iamRequest := &iam.CreateIamTokenRequest{
 		Identity: &iam.CreateIamTokenRequest_Jwt{Jwt: jwtToken},
 	}
 
-	newKey, err := sdk.IAM().IamToken().Create(ctx, iamRequest)
+	newKey, err := c.sdk.IAM().IamToken().Create(ctx, iamRequest)
 	if err != nil {
-		return "", fmt.Errorf("could not create IAM token: %w", err)
+		return "", time.Time{}, fmt.Errorf("could not create IAM token: %w", err)
 	}
 
-	return newKey.IamToken, nil
+	return newKey.IamToken, newKey.GetExpiresAt().AsTime(), nil
+}
+
+// tokenMetrics are the token cache totals as a log group, so that refresh rates can be followed in the logs
+func (c *Client) tokenMetrics() slog.Attr {
+	return slog.Group("token_metrics",
+		slog.Int64("cache_hits", c.tokenCacheHits.Load()),
+		slog.Int64("refreshes", c.tokenRefreshes.Load()),
+		slog.Int64("refresh_errors", c.tokenRefreshErrors.Load()),
+	)
 }
@@ -0,0 +1,24 @@
+package yandex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetIAMToken_Cached(t *testing.T) {
+	client := &Client{
+		token:          "cached",
+		tokenExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	// a refresh would panic on the missing sdk
+	token, err := client.getIAMToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "cached", token)
+	assert.Equal(t, int64(1), client.tokenCacheHits.Load())
+	assert.Equal(t, int64(0), client.tokenRefreshes.Load())
+}
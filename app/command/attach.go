@@ -7,17 +7,20 @@ import (
 	"frank/app/dto"
 	"log/slog"
 	"strings"
+	"unicode/utf8"
 )
 
 type AttachCommand struct {
-	actor    Actor
-	reasoner Reasoner
+	actor      Actor
+	reasoner   Reasoner
+	summarizer Summarizer
 }
 
-func NewAttachCommand(actor Actor, reasoner Reasoner) *AttachCommand {
+func NewAttachCommand(actor Actor, reasoner Reasoner, summarizer Summarizer) *AttachCommand {
 	return &AttachCommand{
-		actor:    actor,
-		reasoner: reasoner,
+		actor:      actor,
+		reasoner:   reasoner,
+		summarizer: summarizer,
 	}
 }
 
@@ -54,10 +57,7 @@ func (c *AttachCommand) Execute(ctx context.Context, prompt dto.Prompt) (string,
 			return "", fmt.Errorf("failed to handle attachment subcommand '%s' %s: %w", cmd.Name, string(cmd.Subcommand), err)
 		}
 
-		prompt = prompt.BranchWithNewAttachment(dto.Attachment{
-			Name:    cmd.Name,
-			Content: output,
-		})
+		prompt = prompt.BranchWithNewAttachment(c.newAttachment(ctx, prompt.Attachments, cmd, output, data.NewPrompt))
 	}
 
 	c.reasoner.Handle(prompt.BranchWithNewText(data.NewPrompt))
@@ -65,6 +65,62 @@ func (c *AttachCommand) Execute(ctx context.Context, prompt dto.Prompt) (string,
 	return "", nil
 }
 
+// newAttachment summarizes oversized outputs with respect to the new prompt, keeping the original for read_attachment.
+// Pages of read_attachment are attached as is, otherwise the original could never be read. They keep the original
+// of the attachment they were read from, so that it can still be paged when the page is attached under the same name.
+func (c *AttachCommand) newAttachment(
+	ctx context.Context,
+	attachments []dto.Attachment,
+	cmd AttachSubcommand,
+	output, newPrompt string,
+) dto.Attachment {
+	attachment := dto.Attachment{
+		Name:    cmd.Name,
+		Content: output,
+	}
+
+	var subcommand struct {
+		Command string `json:"command"`
+		Name    string `json:"name"`
+	}
+
+	if json.Unmarshal(cmd.Subcommand, &subcommand) == nil && subcommand.Command == readAttachmentCommandName {
+		if source := findAttachment(attachments, subcommand.Name); source != nil {
+			attachment.Original = attachmentText(*source)
+		}
+
+		return attachment
+	}
+
+	summary, summarized, err := c.summarizer.Summarize(ctx, output, newPrompt)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to summarize attachment, attaching it as is",
+			slog.String("name", cmd.Name),
+			slog.Any("error", err),
+		)
+
+		return attachment
+	}
+
+	if !summarized {
+		return attachment
+	}
+
+	slog.InfoContext(ctx, "Attachment summarized",
+		slog.String("name", cmd.Name),
+		slog.Int("original_length", utf8.RuneCountInString(output)),
+		slog.Int("summary_length", utf8.RuneCountInString(summary)),
+	)
+
+	attachment.Content = fmt.Sprintf(
+		"[a summary of the %d character result made for this prompt, use read_attachment with name '%s' to read the original]\n%s",
+		utf8.RuneCountInString(output), cmd.Name, summary,
+	)
+	attachment.Original = output
+
+	return attachment
+}
+
 func (c *AttachCommand) Name() string {
 	return "attach"
 }
@@ -98,6 +154,6 @@ func (c *AttachCommand) Description() string {
               description: The name of the subcommand
             subcommand:
               <JSON of the command to schedule, must have a result defined in the spec>
-    description: executes a new prompt with the results of the subcommands attached to it
+    description: executes a new prompt with the results of the subcommands attached to it. Results that are too long are replaced with their summary with respect to new_prompt, the original can be read with read_attachment
   `)
 }
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+)
+
+type CreateWebhookCommand struct {
+	replier        Replier
+	webhookManager WebhookManager
+}
+
+func NewCreateWebhookCommand(replier Replier, webhookManager WebhookManager) *CreateWebhookCommand {
+	return &CreateWebhookCommand{
+		replier:        replier,
+		webhookManager: webhookManager,
+	}
+}
+
+type CreateWebhookCommandData struct {
+	Path   string `json:"path"`
+	Secret string `json:"secret"`
+	Prompt string `json:"prompt"`
+}
+
+func (c *CreateWebhookCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
+	slog.Info("Executing create_webhook command",
+		slog.String("text", prompt.Text),
+	)
+
+	var data CreateWebhookCommandData
+
+	if err := json.Unmarshal([]byte(prompt.Text), &data); err != nil {
+		return "", fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	hook, err := c.webhookManager.CreateWebhook(ctx, data.Path, data.Secret, data.Prompt)
+	if err != nil {
+		return "", fmt.Errorf("create webhook: %w", err)
+	}
+
+	c.replier.Reply(ctx, fmt.Sprintf(
+		"Webhook created: POST %s\nPass the secret '%s' in the X-Webhook-Secret header, the 'secret' query parameter or as an X-Hub-Signature-256 HMAC",
+		c.webhookManager.RoutePath(hook.Path),
+		hook.Secret,
+	))
+
+	return "", nil
+}
+
+func (c *CreateWebhookCommand) Name() string {
+	return "create_webhook"
+}
+
+func (c *CreateWebhookCommand) Description() string {
+	return strings.TrimSpace(`
+    type: object
+    required:
+      - command
+      - path
+      - prompt
+    properties:
+      command:
+        type: string
+        enum: 
+          - create_webhook
+      path:
+        type: string
+        description: Short name of the webhook used in it's URL, alphanumerical, snake-case
+      secret:
+        type: string
+        description: Secret the caller must provide. Leave empty to generate a random one.
+      prompt:
+        type: string
+        description: |
+          Template of the prompt executed on each call. {body} is replaced with the request body.
+        example: "GitHub sent this payload: {body}; summarize and tell me"
+    description: creates an HTTP endpoint that starts a new prompt every time it receives a POST request
+  `)
+}
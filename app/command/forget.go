@@ -0,0 +1,72 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+)
+
+type ForgetCommand struct {
+	replier       Replier
+	knowledgeBase KnowledgeBase
+}
+
+func NewForgetCommand(replier Replier, knowledgeBase KnowledgeBase) *ForgetCommand {
+	return &ForgetCommand{
+		replier:       replier,
+		knowledgeBase: knowledgeBase,
+	}
+}
+
+type ForgetCommandData struct {
+	Name string `json:"name"`
+}
+
+func (c *ForgetCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
+	slog.Info("Executing forget command",
+		slog.String("text", prompt.Text),
+	)
+
+	if !c.knowledgeBase.WritableFrom(prompt.Channel) {
+		return fmt.Sprintf("Error: Knowledge can't be changed from %s prompts.", prompt.Channel), nil
+	}
+
+	var data ForgetCommandData
+
+	if err := json.Unmarshal([]byte(prompt.Text), &data); err != nil {
+		return "", fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	if err := c.knowledgeBase.Forget(ctx, data.Name); err != nil {
+		return fmt.Sprintf("Error: Failed to delete knowledge entry. %s", err.Error()), nil
+	}
+
+	c.replier.Reply(ctx, fmt.Sprintf("Forgot '%s'", data.Name))
+
+	return "", nil
+}
+
+func (c *ForgetCommand) Name() string {
+	return "forget"
+}
+
+func (c *ForgetCommand) Description() string {
+	return strings.TrimSpace(`
+    type: object
+    required:
+      - command
+      - name
+    properties:
+      command:
+        type: string
+        enum: 
+          - forget
+      name:
+        type: string
+        description: knowledge entry name, see list_knowledge
+    description: deletes an entry saved with the remember command. Entries defined in config can't be deleted. Only works in prompts written by the user, not in webhook or email ones.
+  `)
+}
@@ -0,0 +1,175 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"frank/app/dto"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var attachmentReferenceRegexp = regexp.MustCompile(`%attachment\(([^)]+)\)`)
+
+type HTTPRequestPart struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Filename    string `json:"filename,omitempty"` // makes the part a file upload
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// encodeBody builds the request body from one of body, form or multipart and returns it with its content type
+func (c *HTTPRequestCommand) encodeBody(requestData HTTPRequestCommandData, attachments []dto.Attachment) (*bytes.Buffer, string, error) {
+	bodyKinds := 0
+	for _, isSet := range []bool{requestData.Body != nil, len(requestData.Form) > 0, len(requestData.Multipart) > 0} {
+		if isSet {
+			bodyKinds++
+		}
+	}
+
+	if bodyKinds > 1 {
+		return nil, "", fmt.Errorf("only one of body, form and multipart can be set")
+	}
+
+	switch {
+	case requestData.Body != nil:
+		body, err := c.fillValue(*requestData.Body, attachments)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return bytes.NewBufferString(body), "", nil
+	case len(requestData.Form) > 0:
+		values := url.Values{}
+
+		for name, value := range requestData.Form {
+			filled, err := c.fillValue(value, attachments)
+			if err != nil {
+				return nil, "", fmt.Errorf("form field '%s': %w", name, err)
+			}
+
+			values.Set(name, filled)
+		}
+
+		return bytes.NewBufferString(values.Encode()), "application/x-www-form-urlencoded", nil
+	case len(requestData.Multipart) > 0:
+		var body bytes.Buffer
+
+		writer := multipart.NewWriter(&body)
+
+		for _, part := range requestData.Multipart {
+			if part.Name == "" {
+				return nil, "", fmt.Errorf("multipart part without a name")
+			}
+
+			value, err := c.fillValue(part.Value, attachments)
+			if err != nil {
+				return nil, "", fmt.Errorf("multipart part '%s': %w", part.Name, err)
+			}
+
+			if err = writePart(writer, part, value); err != nil {
+				return nil, "", fmt.Errorf("multipart part '%s': %w", part.Name, err)
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, "", fmt.Errorf("writer.Close: %w", err)
+		}
+
+		return &body, writer.FormDataContentType(), nil
+	}
+
+	return nil, "", nil
+}
+
+func writePart(writer *multipart.Writer, part HTTPRequestPart, value string) error {
+	if part.Filename == "" && part.ContentType == "" {
+		return writer.WriteField(part.Name, value)
+	}
+
+	header := make(textproto.MIMEHeader)
+
+	disposition := fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(part.Name))
+	if part.Filename != "" {
+		disposition += fmt.Sprintf(`; filename="%s"`, escapeQuotes(part.Filename))
+	}
+	header.Set("Content-Disposition", disposition)
+
+	contentType := part.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+
+	partWriter, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("CreatePart: %w", err)
+	}
+
+	if _, err = partWriter.Write([]byte(value)); err != nil {
+		return fmt.Errorf("write part: %w", err)
+	}
+
+	return nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+// fillValue replaces secret references first, so that references inside attachment content are left as is
+func (c *HTTPRequestCommand) fillValue(value string, attachments []dto.Attachment) (string, error) {
+	value = c.secretsManager.Fill(value)
+
+	var missing []string
+
+	value = attachmentReferenceRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		name := attachmentReferenceRegexp.FindStringSubmatch(reference)[1]
+
+		for _, attachment := range attachments {
+			if attachment.Name == name {
+				return attachment.Content
+			}
+		}
+
+		missing = append(missing, name)
+
+		return reference
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("attachment '%s' not found", strings.Join(missing, "', '"))
+	}
+
+	return value, nil
+}
+
+// bodySources returns every raw value that ends up in the request body, for secret scope checks
+func bodySources(requestData HTTPRequestCommandData) []string {
+	var sources []string
+
+	if requestData.Body != nil {
+		sources = append(sources, *requestData.Body)
+	}
+
+	names := make([]string, 0, len(requestData.Form))
+	for name := range requestData.Form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sources = append(sources, requestData.Form[name])
+	}
+
+	for _, part := range requestData.Multipart {
+		sources = append(sources, part.Value)
+	}
+
+	return sources
+}
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"frank/app/dto"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecretsManager struct {
+	secrets map[string]string
+}
+
+func (f *fakeSecretsManager) Fill(text string) string {
+	for name, value := range f.secrets {
+		text = strings.ReplaceAll(text, "%frank("+name+")", value)
+	}
+
+	return text
+}
+
+func (f *fakeSecretsManager) Redact(text string) string {
+	for name, value := range f.secrets {
+		text = strings.ReplaceAll(text, value, "%frank("+name+")")
+	}
+
+	return text
+}
+
+func (f *fakeSecretsManager) CheckScope(string, *url.URL, dto.SecretLocation) error {
+	return nil
+}
+
+func TestHTTPRequestCommand_EncodeBody(t *testing.T) {
+	command := &HTTPRequestCommand{
+		secretsManager: &fakeSecretsManager{secrets: map[string]string{"TOKEN": "s3cr3t"}},
+	}
+
+	attachments := []dto.Attachment{
+		{Name: "report", Content: "line 1\nredacted %frank(TOKEN)"},
+	}
+
+	t.Run("form", func(t *testing.T) {
+		body, contentType, err := command.encodeBody(HTTPRequestCommandData{
+			Form: map[string]string{"token": "%frank(TOKEN)", "text": "a b&c"},
+		}, attachments)
+		require.NoError(t, err)
+
+		assert.Equal(t, "application/x-www-form-urlencoded", contentType)
+		assert.Equal(t, "text=a+b%26c&token=s3cr3t", body.String())
+	})
+
+	t.Run("multipart with attachment", func(t *testing.T) {
+		body, contentType, err := command.encodeBody(HTTPRequestCommandData{
+			Multipart: []HTTPRequestPart{
+				{Name: "token", Value: "%frank(TOKEN)"},
+				{Name: "file", Value: "%attachment(report)", Filename: "report.txt", ContentType: "text/plain"},
+			},
+		}, attachments)
+		require.NoError(t, err)
+
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/form-data", mediaType)
+
+		reader := multipart.NewReader(body, params["boundary"])
+
+		part, err := reader.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "token", part.FormName())
+		value, _ := io.ReadAll(part)
+		assert.Equal(t, "s3cr3t", string(value))
+
+		part, err = reader.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "report.txt", part.FileName())
+		assert.Equal(t, "text/plain", part.Header.Get("Content-Type"))
+		value, _ = io.ReadAll(part)
+		// references inside attachments are not filled
+		assert.Equal(t, "line 1\nredacted %frank(TOKEN)", string(value))
+	})
+
+	t.Run("missing attachment", func(t *testing.T) {
+		_, _, err := command.encodeBody(HTTPRequestCommandData{
+			Form: map[string]string{"file": "%attachment(unknown)"},
+		}, attachments)
+		assert.ErrorContains(t, err, "attachment 'unknown' not found")
+	})
+
+	t.Run("body and form together", func(t *testing.T) {
+		body := "raw"
+
+		_, _, err := command.encodeBody(HTTPRequestCommandData{
+			Body: &body,
+			Form: map[string]string{"a": "b"},
+		}, attachments)
+		assert.Error(t, err)
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		body, contentType, err := command.encodeBody(HTTPRequestCommandData{}, attachments)
+		require.NoError(t, err)
+		assert.Nil(t, body)
+		assert.Empty(t, contentType)
+	})
+}
@@ -1,11 +1,12 @@
 package command
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"frank/app/dto"
+	"frank/pkg/egress"
 	"io"
 	"log/slog"
 	"net/http"
@@ -17,12 +18,24 @@ import (
 type HTTPRequestCommand struct {
 	replier        Replier
 	secretsManager SecretsManager
+	egressPolicy   EgressPolicy
+	cookieJars     CookieJarProvider
+	cache          ResultCache
 }
 
-func NewHTTPRequestCommand(replier Replier, secretsManager SecretsManager) *HTTPRequestCommand {
+func NewHTTPRequestCommand(
+	replier Replier,
+	secretsManager SecretsManager,
+	egressPolicy EgressPolicy,
+	cookieJars CookieJarProvider,
+	cache ResultCache,
+) *HTTPRequestCommand {
 	return &HTTPRequestCommand{
 		replier:        replier,
 		secretsManager: secretsManager,
+		egressPolicy:   egressPolicy,
+		cookieJars:     cookieJars,
+		cache:          cache,
 	}
 }
 
@@ -31,13 +44,25 @@ type HTTPRequestCommandData struct {
 	Method  string            `json:"method"`
 	Headers map[string]string `json:"headers,omitempty"`
 	Body    *string           `json:"body,omitempty"`
-	Timeout int               `json:"timeout,omitempty"` // in seconds
+
+	Form      map[string]string `json:"form,omitempty"`
+	Multipart []HTTPRequestPart `json:"multipart,omitempty"`
+
+	Timeout int    `json:"timeout,omitempty"` // in seconds
+	Session string `json:"session,omitempty"` // name of the persisted cookie jar
+	NoCache bool   `json:"no_cache,omitempty"`
+
+	MaxLength int    `json:"max_length,omitempty"` // of the decoded body, in characters
+	JSONPath  string `json:"json_path,omitempty"`
+	Selector  string `json:"selector,omitempty"` // CSS selector for HTML responses
 }
 
 type HTTPRequestResult struct {
-	StatusCode int               `json:"status_code"`
-	Headers    map[string]string `json:"headers"`
-	Body       string            `json:"body"`
+	StatusCode  int               `json:"status_code"`
+	Headers     map[string]string `json:"headers"`
+	ContentType string            `json:"content_type,omitempty"`
+	Body        string            `json:"body"`
+	Truncated   bool              `json:"truncated,omitempty"`
 }
 
 func (c *HTTPRequestCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
@@ -59,28 +84,67 @@ func (c *HTTPRequestCommand) Execute(ctx context.Context, prompt dto.Prompt) (st
 		return "", fmt.Errorf("empty URL")
 	}
 
+	rawURL := requestData.URL
 	requestData.URL = c.secretsManager.Fill(requestData.URL)
 
-	if _, err := url.ParseRequestURI(requestData.URL); err != nil {
+	target, err := url.ParseRequestURI(requestData.URL)
+	if err != nil {
 		return "Error: Invalid URL format. Please provide a valid URL.", nil
 	}
 
+	if err = c.egressPolicy.CheckURL(target); err != nil {
+		logger.WarnContext(ctx, "Egress policy violation",
+			slog.Any("error", err),
+		)
+
+		return fmt.Sprintf("Error: Request blocked, %s. Internal and restricted addresses can't be accessed.", err.Error()), nil
+	}
+
+	if err = c.checkSecretScopes(rawURL, requestData, target); err != nil {
+		logger.WarnContext(ctx, "Secret scope violation",
+			slog.Any("error", err),
+		)
+
+		return fmt.Sprintf("Error: %s. The request was not sent.", err.Error()), nil
+	}
+
 	if requestData.Method == "" {
 		requestData.Method = http.MethodGet
 	}
 
 	logger.DebugContext(ctx, "Creating HTTP client with timeout")
 
-	client := &http.Client{
-		Timeout: time.Duration(requestData.Timeout) * time.Second,
+	client := c.egressPolicy.Client(time.Duration(requestData.Timeout) * time.Second)
+	checkRedirect := client.CheckRedirect
+	// headers and, for 307/308, the body are sent again, so secrets in them must be allowed at the new location
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := checkRedirect(req, via); err != nil {
+			return err
+		}
+
+		return c.checkForwardedSecretScopes(requestData, req.URL)
+	}
+
+	if requestData.Session != "" {
+		if client.Jar, err = c.cookieJars.Jar(ctx, requestData.Session); err != nil {
+			return fmt.Sprintf("Error: Failed to load session '%s'. %s", requestData.Session, err.Error()), nil
+		}
+
+		logger.DebugContext(ctx, "Using cookie jar",
+			slog.String("session", requestData.Session),
+		)
+	}
+
+	body, bodyContentType, err := c.encodeBody(requestData, prompt.Attachments)
+	if err != nil {
+		return fmt.Sprintf("Error: Failed to encode request body. %s", err.Error()), nil
 	}
-	defer client.CloseIdleConnections()
 
 	var bodyReader io.Reader
-	if requestData.Body != nil {
-		bodyReader = bytes.NewReader([]byte(c.secretsManager.Fill(*requestData.Body)))
+	if body != nil {
+		bodyReader = body
 		logger.DebugContext(ctx, "Request body included",
-			slog.Int("body_length", len(*requestData.Body)),
+			slog.Int("body_length", body.Len()),
 		)
 	} else {
 		logger.DebugContext(ctx, "No request body")
@@ -101,13 +165,41 @@ func (c *HTTPRequestCommand) Execute(ctx context.Context, prompt dto.Prompt) (st
 		)
 	}
 
-	if requestData.Body != nil && req.Header.Get("Content-Type") == "" {
+	// form encodings must keep their own content type, the multipart boundary has to match the body
+	if bodyContentType != "" {
+		req.Header.Set("Content-Type", bodyContentType)
+	} else if body != nil && req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "text/plain")
 		logger.DebugContext(ctx, "Set default Content-Type header")
 	}
 
+	// only plain GETs are cached, cookies and credentials make responses session specific
+	cacheStatus := cacheBypass
+	var cacheKey []byte
+
+	if req.Method == http.MethodGet && body == nil && !requestData.NoCache && !isAuthenticatedRequest(rawURL, requestData) {
+		cacheStatus = cacheMiss
+
+		cacheKey, err = json.Marshal([]any{
+			requestData.URL, req.Header, requestData.MaxLength, requestData.JSONPath, requestData.Selector,
+		})
+		if err != nil {
+			return "", fmt.Errorf("json marshal: %w", err)
+		}
+
+		if cached, ok := c.cache.Get(ctx, c.Name(), string(cacheKey)); ok {
+			logger.InfoContext(ctx, "HTTP request command completed from cache",
+				slog.String("cache", cacheHit),
+			)
+
+			return string(cached), nil
+		}
+	}
+
 	startTime := time.Now()
-	logger.InfoContext(ctx, "Sending HTTP request")
+	logger.InfoContext(ctx, "Sending HTTP request",
+		slog.String("cache", cacheStatus),
+	)
 
 	c.replier.Reply(ctx, fmt.Sprintf("Executing http request to '%s'...", requestData.URL))
 
@@ -115,7 +207,9 @@ func (c *HTTPRequestCommand) Execute(ctx context.Context, prompt dto.Prompt) (st
 	duration := time.Since(startTime)
 
 	if err != nil {
-		if strings.Contains(err.Error(), "connection refused") {
+		if errors.Is(err, egress.ErrDenied) {
+			return fmt.Sprintf("Error: Request blocked, %s. Internal and restricted addresses can't be accessed.", err.Error()), nil
+		} else if strings.Contains(err.Error(), "connection refused") {
 			return "Error: Connection refused. The server may be down or the URL may be incorrect.", nil
 		} else if strings.Contains(err.Error(), "no such host") {
 			return "Error: Unknown host. The domain name could not be resolved.", nil
@@ -137,22 +231,33 @@ func (c *HTTPRequestCommand) Execute(ctx context.Context, prompt dto.Prompt) (st
 		slog.Duration("duration", duration),
 	)
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	decoded, err := decodeResponse(resp, responseOptions{
+		MaxLength: requestData.MaxLength,
+		JSONPath:  requestData.JSONPath,
+		Selector:  requestData.Selector,
+	})
 	if err != nil {
-		return "Error: Failed to read response body from the server.", nil
+		logger.WarnContext(ctx, "Failed to decode response body",
+			slog.Any("error", err),
+		)
+
+		return fmt.Sprintf("Error: Failed to process response body from the server. %s", err.Error()), nil
 	}
 
+	// responses echoing a token or a cookie must not reach the cache in plaintext
 	headers := make(map[string]string)
 	for key, values := range resp.Header {
 		if len(values) > 0 {
-			headers[key] = values[0]
+			headers[key] = c.secretsManager.Redact(values[0])
 		}
 	}
 
 	result := HTTPRequestResult{
-		StatusCode: resp.StatusCode,
-		Headers:    headers,
-		Body:       string(bodyBytes),
+		StatusCode:  resp.StatusCode,
+		Headers:     headers,
+		ContentType: decoded.ContentType,
+		Body:        c.secretsManager.Redact(decoded.Body),
+		Truncated:   decoded.Truncated,
 	}
 
 	logger.DebugContext(ctx, "Response details",
@@ -165,13 +270,64 @@ func (c *HTTPRequestCommand) Execute(ctx context.Context, prompt dto.Prompt) (st
 		return "", fmt.Errorf("json marshal: %w", err)
 	}
 
+	if cacheStatus == cacheMiss && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		c.cache.Set(ctx, c.Name(), string(cacheKey), resultJSON)
+	}
+
 	logger.InfoContext(ctx, "HTTP request command completed successfully",
 		slog.Int("result_length", len(resultJSON)),
+		slog.String("cache", cacheStatus),
 	)
 
 	return string(resultJSON), nil
 }
 
+// isAuthenticatedRequest reports whether the response may depend on who is asking, rawURL is the URL before filling secrets
+func isAuthenticatedRequest(rawURL string, requestData HTTPRequestCommandData) bool {
+	if requestData.Session != "" || strings.Contains(rawURL, "%frank(") {
+		return true
+	}
+
+	for key, value := range requestData.Headers {
+		if strings.EqualFold(key, "Cookie") || strings.EqualFold(key, "Authorization") || strings.Contains(value, "%frank(") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSecretScopes verifies that every secret referenced in the request may be sent to the target
+func (c *HTTPRequestCommand) checkSecretScopes(rawURL string, requestData HTTPRequestCommandData, target *url.URL) error {
+	rawPath, rawQuery, _ := strings.Cut(rawURL, "?")
+
+	if err := c.secretsManager.CheckScope(rawPath, target, dto.PathSecretLocation); err != nil {
+		return err
+	}
+
+	if err := c.secretsManager.CheckScope(rawQuery, target, dto.QuerySecretLocation); err != nil {
+		return err
+	}
+
+	return c.checkForwardedSecretScopes(requestData, target)
+}
+
+func (c *HTTPRequestCommand) checkForwardedSecretScopes(requestData HTTPRequestCommandData, target *url.URL) error {
+	for _, value := range requestData.Headers {
+		if err := c.secretsManager.CheckScope(value, target, dto.HeaderSecretLocation); err != nil {
+			return err
+		}
+	}
+
+	for _, source := range bodySources(requestData) {
+		if err := c.secretsManager.CheckScope(source, target, dto.BodySecretLocation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *HTTPRequestCommand) Name() string {
 	return "http_request"
 }
@@ -204,11 +360,55 @@ func (c *HTTPRequestCommand) Description() string {
         type: string
         nullable: true
         description: Request body content. Set to null to omit body.
+      form:
+        type: object
+        additionalProperties:
+          type: string
+        description: Fields sent as application/x-www-form-urlencoded body. Can't be combined with body or multipart.
+      multipart:
+        type: array
+        description: Parts sent as multipart/form-data body, e.g. for file uploads. Can't be combined with body or form.
+        items:
+          type: object
+          required:
+            - name
+            - value
+          properties:
+            name:
+              type: string
+            value:
+              type: string
+            filename:
+              type: string
+              description: Sends the part as a file with this name
+            content_type:
+              type: string
+              description: Content type of a file part, application/octet-stream by default
       timeout:
         type: integer
         minimum: 1
         description: Request timeout in seconds
-    description: Executes an HTTP request and returns the response with status code, headers, and body. Can replace vars with secrets.
+      no_cache:
+        type: boolean
+        default: false
+        description: Skip a recently cached response. Only GET requests without body, session, credentials and secrets are cached.
+      session:
+        type: string
+        pattern: ^[a-zA-Z0-9_-]{1,64}$
+        description: Name of a persisted cookie jar. Cookies are sent from it and Set-Cookie responses update it, so logins survive between requests, prompts and scheduled runs.
+      max_length:
+        type: integer
+        minimum: 1
+        maximum: 100000
+        default: 20000
+        description: Maximum length of the returned body in characters, longer bodies are truncated
+      json_path:
+        type: string
+        description: JSONPath expression applied to JSON responses, e.g. $.items[*].name. Only the matches are returned.
+      selector:
+        type: string
+        description: CSS selector applied to HTML responses, e.g. "article h2". Only the text of matching elements is returned.
+    description: Executes an HTTP request and returns the response with status code, headers, and body. JSON is pretty-printed, HTML is converted to readable text and binary content is summarized. Can replace vars with secrets, but only on the hosts and in the request parts the secret is allowed for. Body, form and multipart values can include the content of an earlier attachment with %attachment(name).
 
     RESULT SPEC:
 
@@ -226,10 +426,17 @@ func (c *HTTPRequestCommand) Description() string {
         example:
           Content-Type: application/json
           Cache-Control: no-cache
+      content_type:
+        type: string
+        description: Media type of the response
+        example: application/json
       body:
         type: string
-        description: HTTP response body
+        description: Decoded HTTP response body
         example: '{"message": "Success"}'
+      truncated:
+        type: boolean
+        description: Whether the body was cut to max_length
     required:
       - status_code
       - headers
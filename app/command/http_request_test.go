@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"frank/app/dto"
+	"frank/pkg/config"
+	"frank/pkg/egress"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCache struct {
+	values map[string][]byte
+}
+
+func (f *fakeCache) Get(_ context.Context, command, key string) ([]byte, bool) {
+	value, ok := f.values[command+key]
+	return value, ok
+}
+
+func (f *fakeCache) Set(_ context.Context, command, key string, value []byte) {
+	f.values[command+key] = value
+}
+
+func TestHTTPRequestCommand_Execute_Cache(t *testing.T) {
+	// the server leaks a secret no matter who asks
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Debug-Token", "s3cr3t")
+		_, _ = fmt.Fprint(w, `{"token":"s3cr3t"}`)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Egress.Schemes = []string{"http", "https"}
+	cfg.Egress.AllowCIDRs = []string{"127.0.0.0/8"}
+
+	policy, err := egress.NewPolicy(cfg)
+	require.NoError(t, err)
+
+	newCommand := func(cache *fakeCache) *HTTPRequestCommand {
+		return NewHTTPRequestCommand(
+			&fakeReplier{},
+			&fakeSecretsManager{secrets: map[string]string{"TOKEN": "s3cr3t"}},
+			policy,
+			nil,
+			cache,
+		)
+	}
+
+	t.Run("cached response is redacted", func(t *testing.T) {
+		cache := &fakeCache{values: make(map[string][]byte)}
+
+		output, err := newCommand(cache).Execute(context.Background(), dto.Prompt{
+			Text: `{"command":"http_request","url":"` + server.URL + `","method":"GET"}`,
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "%frank(TOKEN)")
+		assert.NotContains(t, output, "s3cr3t")
+
+		require.Len(t, cache.values, 1)
+		for _, value := range cache.values {
+			assert.Contains(t, string(value), "%frank(TOKEN)")
+			assert.NotContains(t, string(value), "s3cr3t")
+		}
+	})
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"authorization header", `{"command":"http_request","url":"` + server.URL + `","method":"GET","headers":{"authorization":"Bearer abc"}}`},
+		{"cookie header", `{"command":"http_request","url":"` + server.URL + `","method":"GET","headers":{"Cookie":"sessionid=abc"}}`},
+		{"secret in a header", `{"command":"http_request","url":"` + server.URL + `","method":"GET","headers":{"X-Api-Key":"%frank(TOKEN)"}}`},
+		{"secret in the url", `{"command":"http_request","url":"` + server.URL + `?key=%frank(TOKEN)","method":"GET"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run("not cached with "+tt.name, func(t *testing.T) {
+			cache := &fakeCache{values: make(map[string][]byte)}
+
+			_, err := newCommand(cache).Execute(context.Background(), dto.Prompt{Text: tt.text})
+			require.NoError(t, err)
+
+			assert.Empty(t, cache.values)
+		})
+	}
+}
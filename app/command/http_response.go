@@ -0,0 +1,319 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// responses are never read past this limit, even if the model asks for a longer body
+var maxDownloadBytes int64 = 5 << 20
+
+// decoded body length returned to the model by default, in characters
+var defaultMaxBodyLength = 20000
+var maxBodyLength = 100000
+
+type responseOptions struct {
+	MaxLength int
+	JSONPath  string
+	Selector  string
+}
+
+type decodedResponse struct {
+	ContentType string
+	Body        string
+	Truncated   bool
+}
+
+// decodeResponse reads a bounded part of the body and turns it into text the model can work with
+func decodeResponse(resp *http.Response, opts responseOptions) (decodedResponse, error) {
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil {
+		return decodedResponse{}, fmt.Errorf("read body: %w", err)
+	}
+
+	downloadTruncated := int64(len(raw)) > maxDownloadBytes
+	if downloadTruncated {
+		// a rune cut in half would make the whole body look like a legacy charset
+		raw = trimIncompleteRune(raw[:maxDownloadBytes])
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" && len(raw) > 0 {
+		contentType = http.DetectContentType(raw)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	result := decodedResponse{ContentType: mediaType}
+
+	if len(raw) == 0 {
+		return result, nil
+	}
+
+	if !isTextMediaType(mediaType) {
+		size := fmt.Sprintf("%d bytes", len(raw))
+		if downloadTruncated {
+			size = fmt.Sprintf("more than %d bytes", maxDownloadBytes)
+		}
+
+		result.Body = fmt.Sprintf("[binary content: %s, %s]", mediaType, size)
+
+		return result, nil
+	}
+
+	text, err := toUTF8(raw, contentType)
+	if err != nil {
+		return decodedResponse{}, fmt.Errorf("toUTF8: %w", err)
+	}
+
+	switch {
+	case isJSONMediaType(mediaType):
+		text, err = decodeJSON(text, opts.JSONPath)
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		text, err = decodeHTML(text, opts.Selector)
+	case opts.JSONPath != "" || opts.Selector != "":
+		err = fmt.Errorf("json_path and selector are not supported for %s", mediaType)
+	}
+
+	if err != nil {
+		return decodedResponse{}, err
+	}
+
+	maxLength := opts.MaxLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxBodyLength
+	}
+	maxLength = min(maxLength, maxBodyLength)
+
+	result.Body, result.Truncated = truncateText(text, maxLength)
+
+	if downloadTruncated && !result.Truncated {
+		result.Body += fmt.Sprintf("\n...[truncated, response is larger than %d bytes]", maxDownloadBytes)
+		result.Truncated = true
+	}
+
+	return result, nil
+}
+
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		isJSONMediaType(mediaType) ||
+		mediaType == "application/xml" ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/javascript" ||
+		mediaType == "application/x-www-form-urlencoded"
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// trimIncompleteRune removes a multi-byte UTF-8 sequence left unfinished at the end of the data
+func trimIncompleteRune(raw []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(raw); i++ {
+		start := len(raw) - i
+		if !utf8.RuneStart(raw[start]) {
+			continue
+		}
+
+		if !utf8.FullRune(raw[start:]) {
+			return raw[:start]
+		}
+
+		break
+	}
+
+	return raw
+}
+
+// toUTF8 converts the body using the charset from the header, meta tags or BOM
+func toUTF8(raw []byte, contentType string) (string, error) {
+	if utf8.Valid(raw) && !strings.Contains(strings.ToLower(contentType), "charset=") {
+		return string(raw), nil
+	}
+
+	reader, err := charset.NewReader(bytes.NewReader(raw), contentType)
+	if err != nil {
+		return "", fmt.Errorf("charset.NewReader: %w", err)
+	}
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("read decoded body: %w", err)
+	}
+
+	return string(decoded), nil
+}
+
+func decodeJSON(text, jsonPath string) (string, error) {
+	if jsonPath == "" {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, []byte(text), "", "  "); err != nil {
+			// malformed JSON is still useful as is
+			return text, nil //nolint:nilerr
+		}
+
+		return indented.String(), nil
+	}
+
+	path, err := jp.ParseString(jsonPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid json_path: %w", err)
+	}
+
+	data, err := oj.ParseString(text)
+	if err != nil {
+		return "", fmt.Errorf("json_path can't be applied, body is not valid JSON: %w", err)
+	}
+
+	return oj.JSON(path.Get(data), &oj.Options{Indent: 2, Sort: true}), nil
+}
+
+func decodeHTML(text, selector string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(text))
+	if err != nil {
+		return "", fmt.Errorf("parse html: %w", err)
+	}
+
+	if selector == "" {
+		return htmlToText(doc.Selection), nil
+	}
+
+	matcher, err := cascadia.Compile(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+
+	var parts []string
+
+	doc.FindMatcher(matcher).Each(func(_ int, s *goquery.Selection) {
+		if part := htmlToText(s); part != "" {
+			parts = append(parts, part)
+		}
+	})
+
+	if len(parts) == 0 {
+		return fmt.Sprintf("[no elements match selector %q]", selector), nil
+	}
+
+	return strings.Join(parts, "\n\n"), nil
+}
+
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true, "head": true, "iframe": true,
+}
+
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true, "main": true,
+	"nav": true, "aside": true, "ul": true, "ol": true, "li": true, "table": true, "tr": true, "br": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "pre": true, "blockquote": true,
+	"form": true, "hr": true, "dl": true, "dt": true, "dd": true, "figure": true, "figcaption": true,
+}
+
+// htmlToText keeps visible text, one line per block element, and link targets in parentheses
+func htmlToText(selection *goquery.Selection) string {
+	var builder strings.Builder
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			text := strings.Join(strings.Fields(node.Data), " ")
+			if text == "" {
+				builder.WriteString(" ")
+				return
+			}
+
+			if unicode.IsSpace(rune(node.Data[0])) {
+				builder.WriteString(" ")
+			}
+
+			builder.WriteString(text)
+
+			if unicode.IsSpace(rune(node.Data[len(node.Data)-1])) {
+				builder.WriteString(" ")
+			}
+
+			return
+		case html.ElementNode:
+			if skippedElements[node.Data] {
+				return
+			}
+		}
+
+		isBlock := node.Type == html.ElementNode && blockElements[node.Data]
+		if isBlock {
+			builder.WriteString("\n")
+		}
+
+		if node.Type == html.ElementNode && node.Data == "li" {
+			builder.WriteString("- ")
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+
+		if node.Type == html.ElementNode && node.Data == "a" {
+			for _, attr := range node.Attr {
+				if attr.Key == "href" && attr.Val != "" && !strings.HasPrefix(attr.Val, "#") && !strings.HasPrefix(attr.Val, "javascript:") {
+					builder.WriteString(" (" + attr.Val + ") ")
+				}
+			}
+		}
+
+		if node.Type == html.ElementNode && (node.Data == "td" || node.Data == "th") {
+			builder.WriteString(" | ")
+		}
+
+		if isBlock {
+			builder.WriteString("\n")
+		}
+	}
+
+	for _, node := range selection.Nodes {
+		walk(node)
+	}
+
+	return removeBlankLines(builder.String())
+}
+
+func removeBlankLines(text string) string {
+	lines := strings.Split(text, "\n")
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+
+		result = append(result, line)
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// truncateText cuts the text to maxLength characters and appends a marker with the number of omitted ones
+func truncateText(text string, maxLength int) (string, bool) {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text, false
+	}
+
+	return string(runes[:maxLength]) + fmt.Sprintf("\n...[truncated, %d more characters]", len(runes)-maxLength), true
+}
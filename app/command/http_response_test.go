@@ -0,0 +1,153 @@
+package command
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResponse(contentType string, body []byte) *http.Response {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	return &http.Response{
+		Header: header,
+		Body:   io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		opts        responseOptions
+		expected    string
+		truncated   bool
+		wantErr     bool
+	}{
+		{
+			name:        "json is pretty printed",
+			contentType: "application/json",
+			body:        []byte(`{"a":1}`),
+			expected:    "{\n  \"a\": 1\n}",
+		},
+		{
+			name:        "json path",
+			contentType: "application/json; charset=utf-8",
+			body:        []byte(`{"items":[{"name":"first"},{"name":"second"}]}`),
+			opts:        responseOptions{JSONPath: "$.items[*].name"},
+			expected:    "[\n  \"first\",\n  \"second\"\n]",
+		},
+		{
+			name:        "invalid json path",
+			contentType: "application/json",
+			body:        []byte(`{}`),
+			opts:        responseOptions{JSONPath: "$.["},
+			wantErr:     true,
+		},
+		{
+			name:        "html to text",
+			contentType: "text/html",
+			body: []byte(`<html><head><title>t</title><style>p{}</style></head><body>
+				<h1>Title</h1><p>Hello <b>world</b>, see <a href="/docs">docs</a>.</p>
+				<ul><li>one</li><li>two</li></ul><script>alert(1)</script></body></html>`),
+			expected: "Title\nHello world, see docs (/docs) .\n- one\n- two",
+		},
+		{
+			name:        "css selector",
+			contentType: "text/html",
+			body:        []byte(`<div><span class="price">10</span><span class="price">20</span><span>x</span></div>`),
+			opts:        responseOptions{Selector: ".price"},
+			expected:    "10\n\n20",
+		},
+		{
+			name:        "charset conversion",
+			contentType: "text/plain; charset=windows-1251",
+			body:        []byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2},
+			expected:    "Привет",
+		},
+		{
+			name:        "binary summary",
+			contentType: "image/png",
+			body:        []byte{0x89, 'P', 'N', 'G', 0, 0, 0},
+			expected:    "[binary content: image/png, 7 bytes]",
+		},
+		{
+			name:     "content type is sniffed",
+			body:     []byte("%PDF-1.4 something"),
+			expected: "[binary content: application/pdf, 18 bytes]",
+		},
+		{
+			name:        "truncated with marker",
+			contentType: "text/plain",
+			body:        []byte(strings.Repeat("a", 15)),
+			opts:        responseOptions{MaxLength: 10},
+			expected:    strings.Repeat("a", 10) + "\n...[truncated, 5 more characters]",
+			truncated:   true,
+		},
+		{
+			name:        "selector on plain text",
+			contentType: "text/plain",
+			body:        []byte("text"),
+			opts:        responseOptions{Selector: "p"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := decodeResponse(newTestResponse(tt.contentType, tt.body), tt.opts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Body)
+			assert.Equal(t, tt.truncated, result.Truncated)
+		})
+	}
+}
+
+func TestDecodeResponse_TruncatedUTF8(t *testing.T) {
+	defer func(limit int64) { maxDownloadBytes = limit }(maxDownloadBytes)
+	maxDownloadBytes = 15
+
+	// 7 two-byte runes fill 14 bytes, the limit cuts the 8th one in half
+	body := []byte(strings.Repeat("я", 10))
+
+	result, err := decodeResponse(newTestResponse("text/plain", body), responseOptions{})
+	require.NoError(t, err)
+
+	assert.True(t, result.Truncated)
+	assert.True(t, strings.HasPrefix(result.Body, strings.Repeat("я", 7)+"\n...[truncated"), result.Body)
+}
+
+func TestTrimIncompleteRune(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      []byte
+		expected []byte
+	}{
+		{"ascii", []byte("abc"), []byte("abc")},
+		{"complete rune", []byte("aя"), []byte("aя")},
+		{"half of a two-byte rune", []byte("aя")[:2], []byte("a")},
+		{"two bytes of a four-byte rune", []byte("a😀")[:3], []byte("a")},
+		{"three bytes of a four-byte rune", []byte("a😀")[:4], []byte("a")},
+		{"empty", []byte{}, []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, trimIncompleteRune(tt.raw))
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"frank/app/dto"
 	"frank/pkg/database"
+	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -28,8 +30,44 @@ type Scheduler interface {
 
 type SecretsManager interface {
 	Fill(text string) string
+	Redact(text string) string
+	CheckScope(text string, target *url.URL, location dto.SecretLocation) error
+}
+
+type EgressPolicy interface {
+	Client(timeout time.Duration) *http.Client
+	CheckURL(target *url.URL) error
+}
+
+type CookieJarProvider interface {
+	Jar(ctx context.Context, session string) (http.CookieJar, error)
+}
+
+type ResultCache interface {
+	Get(ctx context.Context, command, key string) ([]byte, bool)
+	Set(ctx context.Context, command, key string, value []byte)
 }
 
 type WebSearchEngine interface {
-	WebSearch(ctx context.Context, query string) (string, error)
+	WebSearch(ctx context.Context, query string, opts dto.WebSearchOptions) ([]dto.WebSearchResult, error)
+}
+
+type WebhookManager interface {
+	CreateWebhook(ctx context.Context, path, secret, prompt string) (database.Webhook, error)
+	RoutePath(path string) string
+}
+
+type EmailSender interface {
+	SendEmail(ctx context.Context, to []string, subject, body string) error
+}
+
+type KnowledgeBase interface {
+	List(ctx context.Context) ([]dto.KnowledgeEntry, error)
+	Remember(ctx context.Context, name, content string) error
+	Forget(ctx context.Context, name string) error
+	WritableFrom(channel string) bool
+}
+
+type Summarizer interface {
+	Summarize(ctx context.Context, text, task string) (string, bool, error)
 }
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+)
+
+type ListKnowledgeCommand struct {
+	knowledgeBase KnowledgeBase
+}
+
+func NewListKnowledgeCommand(knowledgeBase KnowledgeBase) *ListKnowledgeCommand {
+	return &ListKnowledgeCommand{
+		knowledgeBase: knowledgeBase,
+	}
+}
+
+func (c *ListKnowledgeCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
+	slog.Info("Executing list_knowledge command",
+		slog.String("text", prompt.Text),
+	)
+
+	entries, err := c.knowledgeBase.List(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list knowledge: %w", err)
+	}
+
+	result, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal knowledge entries: %w", err)
+	}
+
+	return string(result), nil
+}
+
+func (c *ListKnowledgeCommand) Name() string {
+	return "list_knowledge"
+}
+
+func (c *ListKnowledgeCommand) Description() string {
+	return strings.TrimSpace(`
+    type: object
+    required:
+      - command
+    properties:
+      command:
+        type: string
+        enum: 
+          - list_knowledge
+    description: returns all knowledge base entries with their names, contents and sources (base, config or database). Only database entries can be changed with remember and forget. Returns result as a JSON string. This command will not display anything to the user, for this you MUST also use 'attach' and 'reply' commands.
+  `)
+}
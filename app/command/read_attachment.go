@@ -0,0 +1,117 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+)
+
+const readAttachmentCommandName = "read_attachment"
+
+var defaultPageLength = 10000
+var maxPageLength = 20000
+
+type ReadAttachmentCommand struct{}
+
+func NewReadAttachmentCommand() *ReadAttachmentCommand {
+	return &ReadAttachmentCommand{}
+}
+
+type ReadAttachmentCommandData struct {
+	Name   string `json:"name"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+func (c *ReadAttachmentCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
+	slog.Info("Executing read_attachment command",
+		slog.String("text", prompt.Text),
+	)
+
+	var data ReadAttachmentCommandData
+
+	if err := json.Unmarshal([]byte(prompt.Text), &data); err != nil {
+		return "", fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	if data.Length <= 0 {
+		data.Length = defaultPageLength
+	}
+
+	data.Length = min(data.Length, maxPageLength)
+
+	if data.Offset < 0 {
+		return "Error: offset must not be negative", nil
+	}
+
+	found := findAttachment(prompt.Attachments, data.Name)
+	if found == nil {
+		names := make([]string, 0, len(prompt.Attachments))
+		for _, att := range prompt.Attachments {
+			names = append(names, att.Name)
+		}
+
+		return fmt.Sprintf("Error: attachment '%s' not found, available: %s", data.Name, strings.Join(names, ", ")), nil
+	}
+
+	runes := []rune(attachmentText(*found))
+
+	if data.Offset >= len(runes) {
+		return fmt.Sprintf("Error: offset %d is beyond the end of the attachment, it has %d characters", data.Offset, len(runes)), nil
+	}
+
+	end := min(data.Offset+data.Length, len(runes))
+
+	return fmt.Sprintf("[characters %d-%d of %d of '%s']\n%s", data.Offset, end, len(runes), data.Name, string(runes[data.Offset:end])), nil
+}
+
+// findAttachment returns the newest attachment with the name, attachments are ordered newest first
+func findAttachment(attachments []dto.Attachment, name string) *dto.Attachment {
+	for i := range attachments {
+		if attachments[i].Name == name {
+			return &attachments[i]
+		}
+	}
+
+	return nil
+}
+
+// attachmentText is the full text of the attachment, which is the original if the content is a summary or a page
+func attachmentText(attachment dto.Attachment) string {
+	if attachment.Original != "" {
+		return attachment.Original
+	}
+
+	return attachment.Content
+}
+
+func (c *ReadAttachmentCommand) Name() string {
+	return readAttachmentCommandName
+}
+
+func (c *ReadAttachmentCommand) Description() string {
+	return strings.TrimSpace(fmt.Sprintf(`
+    type: object
+    required:
+      - command
+      - name
+    properties:
+      command:
+        type: string
+        enum: 
+          - read_attachment
+      name:
+        type: string
+        description: name of an attachment of the current prompt
+      offset:
+        type: integer
+        description: the first character to return, 0 by default
+      length:
+        type: integer
+        description: the number of characters to return, %d by default and at most %d
+    description: returns a page of the original content of an attachment, use it when the summary of the attachment lacks details. Returns result as a string starting with the returned character range. This command will not display anything to the user, for this you MUST also use 'attach' and 'reply' commands.
+  `, defaultPageLength, maxPageLength))
+}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"frank/app/dto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadAttachmentCommand_Execute(t *testing.T) {
+	prompt := dto.Prompt{
+		Attachments: []dto.Attachment{
+			{Name: "page", Content: "a summary", Original: "0123456789"},
+			{Name: "search", Content: "short result"},
+			{Name: "page", Content: "older", Original: "older original"},
+		},
+	}
+
+	cmd := NewReadAttachmentCommand()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"the newest original", `{"command":"read_attachment","name":"page","offset":2,"length":5}`, "[characters 2-7 of 10 of 'page']\n23456"},
+		{"page is cut at the end", `{"command":"read_attachment","name":"page","offset":8}`, "[characters 8-10 of 10 of 'page']\n89"},
+		{"not summarized content", `{"command":"read_attachment","name":"search"}`, "[characters 0-12 of 12 of 'search']\nshort result"},
+		{"unknown name", `{"command":"read_attachment","name":"other"}`, "Error: attachment 'other' not found, available: page, search, page"},
+		{"offset beyond the end", `{"command":"read_attachment","name":"page","offset":10}`, "Error: offset 10 is beyond the end of the attachment, it has 10 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := cmd.Execute(context.Background(), prompt.BranchWithNewText(tt.text))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+// fakeActor runs read_attachment and returns the original for any other command
+type fakeActor struct {
+	original string
+}
+
+func (a *fakeActor) Handle(ctx context.Context, prompt dto.Prompt) (string, error) {
+	if strings.Contains(prompt.Text, readAttachmentCommandName) {
+		return NewReadAttachmentCommand().Execute(ctx, prompt)
+	}
+
+	return a.original, nil
+}
+
+type fakeSummarizer struct{}
+
+func (s *fakeSummarizer) Summarize(_ context.Context, text, _ string) (string, bool, error) {
+	if len(text) <= 5 {
+		return text, false, nil
+	}
+
+	return "a summary", true, nil
+}
+
+type fakeReasoner struct {
+	prompts []dto.Prompt
+}
+
+func (r *fakeReasoner) Handle(prompt dto.Prompt) {
+	r.prompts = append(r.prompts, prompt)
+}
+
+func TestReadAttachmentCommand_PageUnderTheSameName(t *testing.T) {
+	reasoner := &fakeReasoner{}
+	attach := NewAttachCommand(&fakeActor{original: "0123456789"}, reasoner, &fakeSummarizer{})
+
+	_, err := attach.Execute(context.Background(), dto.Prompt{Text: `{"command":"attach","new_prompt":"read it","list":[
+		{"name":"page","subcommand":{"command":"http_request","url":"https://example.com"}}
+	]}`})
+	require.NoError(t, err)
+	require.Len(t, reasoner.prompts, 1)
+	assert.Equal(t, "0123456789", reasoner.prompts[0].Attachments[0].Original)
+
+	// the model attaches a page under the name of the summarized original
+	prompt := reasoner.prompts[0].BranchWithNewText(`{"command":"attach","new_prompt":"read more","list":[
+		{"name":"page","subcommand":{"command":"read_attachment","name":"page","length":3}}
+	]}`)
+
+	_, err = attach.Execute(context.Background(), prompt)
+	require.NoError(t, err)
+	require.Len(t, reasoner.prompts, 2)
+
+	page := reasoner.prompts[1].Attachments[0]
+	assert.Equal(t, "[characters 0-3 of 10 of 'page']\n012", page.Content)
+
+	output, err := NewReadAttachmentCommand().Execute(context.Background(),
+		reasoner.prompts[1].BranchWithNewText(`{"command":"read_attachment","name":"page","offset":5}`))
+	require.NoError(t, err)
+	assert.Equal(t, "[characters 5-10 of 10 of 'page']\n56789", output)
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+)
+
+type RememberCommand struct {
+	replier       Replier
+	knowledgeBase KnowledgeBase
+}
+
+func NewRememberCommand(replier Replier, knowledgeBase KnowledgeBase) *RememberCommand {
+	return &RememberCommand{
+		replier:       replier,
+		knowledgeBase: knowledgeBase,
+	}
+}
+
+type RememberCommandData struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func (c *RememberCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
+	slog.Info("Executing remember command",
+		slog.String("text", prompt.Text),
+	)
+
+	if !c.knowledgeBase.WritableFrom(prompt.Channel) {
+		return fmt.Sprintf("Error: Knowledge can't be changed from %s prompts.", prompt.Channel), nil
+	}
+
+	var data RememberCommandData
+
+	if err := json.Unmarshal([]byte(prompt.Text), &data); err != nil {
+		return "", fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	if err := c.knowledgeBase.Remember(ctx, data.Name, data.Content); err != nil {
+		return fmt.Sprintf("Error: Failed to save knowledge entry. %s", err.Error()), nil
+	}
+
+	c.replier.Reply(ctx, fmt.Sprintf("Remembered '%s'", data.Name))
+
+	return "", nil
+}
+
+func (c *RememberCommand) Name() string {
+	return "remember"
+}
+
+func (c *RememberCommand) Description() string {
+	return strings.TrimSpace(`
+    type: object
+    required:
+      - command
+      - name
+      - content
+    properties:
+      command:
+        type: string
+        enum: 
+          - remember
+      name:
+        type: string
+        description: Short name of the entry, alphanumerical, snake-case. An entry with the same name is replaced.
+      content:
+        type: string
+        description: The fact or instruction to remember, self-contained, e.g. "User's steam ID is 123" or an API recipe with example requests. Use %frank(name) references instead of secret values.
+    description: saves a fact to the knowledge base, relevant entries are added to the context of future prompts. Use it when the user asks to remember something. Only works in prompts written by the user, not in webhook or email ones.
+  `)
+}
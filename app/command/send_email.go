@@ -0,0 +1,84 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+)
+
+type SendEmailCommand struct {
+	replier     Replier
+	emailSender EmailSender
+}
+
+func NewSendEmailCommand(replier Replier, emailSender EmailSender) *SendEmailCommand {
+	return &SendEmailCommand{
+		replier:     replier,
+		emailSender: emailSender,
+	}
+}
+
+type SendEmailCommandData struct {
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+}
+
+func (c *SendEmailCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
+	slog.Info("Executing send_email command",
+		slog.String("text", prompt.Text),
+	)
+
+	var data SendEmailCommandData
+
+	if err := json.Unmarshal([]byte(prompt.Text), &data); err != nil {
+		return "", fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	if data.Body == "" {
+		return "", fmt.Errorf("empty body")
+	}
+
+	if err := c.emailSender.SendEmail(ctx, data.To, data.Subject, data.Body); err != nil {
+		return "", fmt.Errorf("send email: %w", err)
+	}
+
+	c.replier.Reply(ctx, fmt.Sprintf("Email '%s' was sent to %s", data.Subject, strings.Join(data.To, ", ")))
+
+	return "", nil
+}
+
+func (c *SendEmailCommand) Name() string {
+	return "send_email"
+}
+
+func (c *SendEmailCommand) Description() string {
+	return strings.TrimSpace(`
+    type: object
+    required:
+      - command
+      - to
+      - subject
+      - body
+    properties:
+      command:
+        type: string
+        enum: 
+          - send_email
+      to:
+        type: array
+        items:
+          type: string
+        description: Recipient email addresses, only the ones allowed in config are accepted
+      subject:
+        type: string
+        description: Subject of the email
+      body:
+        type: string
+        description: Plain text body of the email
+    description: sends an email
+  `)
+}
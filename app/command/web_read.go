@@ -0,0 +1,291 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frank/app/dto"
+	"frank/pkg/egress"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	"github.com/go-shiori/go-readability"
+)
+
+// length of one page of the extracted content, in characters
+var webReadPageLength = 8000
+
+var webReadTimeout = 30 * time.Second
+
+var webReadUserAgent = "Mozilla/5.0 (compatible; frank)"
+
+type WebReadCommand struct {
+	replier      Replier
+	egressPolicy EgressPolicy
+}
+
+func NewWebReadCommand(replier Replier, egressPolicy EgressPolicy) *WebReadCommand {
+	return &WebReadCommand{
+		replier:      replier,
+		egressPolicy: egressPolicy,
+	}
+}
+
+type WebReadCommandData struct {
+	URL  string `json:"url"`
+	Page int    `json:"page,omitempty"`
+}
+
+type webPage struct {
+	Title     string
+	URL       string
+	SiteName  string
+	Byline    string
+	Published *time.Time
+	Content   string
+}
+
+func (c *WebReadCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
+	logger := slog.With(
+		slog.String("command", c.Name()),
+		slog.String("prompt_id", prompt.ID.String()),
+	)
+
+	logger.InfoContext(ctx, "Executing web_read command",
+		slog.String("text", prompt.Text),
+	)
+
+	var requestData WebReadCommandData
+	if err := json.Unmarshal([]byte(prompt.Text), &requestData); err != nil {
+		return "", fmt.Errorf("json unmarshal: %w", err)
+	}
+
+	if requestData.Page < 1 {
+		requestData.Page = 1
+	}
+
+	target, err := url.ParseRequestURI(requestData.URL)
+	if err != nil {
+		return "Error: Invalid URL format. Please provide a valid URL.", nil
+	}
+
+	if err = c.egressPolicy.CheckURL(target); err != nil {
+		return fmt.Sprintf("Error: Request blocked, %s. Internal and restricted addresses can't be accessed.", err.Error()), nil
+	}
+
+	c.replier.Reply(ctx, fmt.Sprintf("Reading web page '%s'...", requestData.URL))
+
+	page, err := c.fetch(ctx, target)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to read web page",
+			slog.Any("error", err),
+		)
+
+		if errors.Is(err, egress.ErrDenied) {
+			return fmt.Sprintf("Error: Request blocked, %s. Internal and restricted addresses can't be accessed.", err.Error()), nil
+		}
+
+		return fmt.Sprintf("Error: Failed to read web page. %s", err.Error()), nil
+	}
+
+	chunks := splitIntoPages(page.Content, webReadPageLength)
+	if requestData.Page > len(chunks) {
+		return fmt.Sprintf("Error: Page %d doesn't exist, the content has %d pages.", requestData.Page, len(chunks)), nil
+	}
+
+	logger.InfoContext(ctx, "Web page read",
+		slog.String("url", page.URL),
+		slog.Int("content_length", len(page.Content)),
+		slog.Int("pages", len(chunks)),
+	)
+
+	return formatWebPage(page, chunks[requestData.Page-1], requestData.Page, len(chunks)), nil
+}
+
+func (c *WebReadCommand) fetch(ctx context.Context, target *url.URL) (webPage, error) {
+	client := c.egressPolicy.Client(webReadTimeout)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return webPage{}, fmt.Errorf("http.NewRequest: %w", err)
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("User-Agent", webReadUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return webPage{}, fmt.Errorf("client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return webPage{}, fmt.Errorf("server responded with status %d", resp.StatusCode)
+	}
+
+	// redirects are followed, links must be resolved against the final location
+	finalURL := resp.Request.URL
+	page := webPage{URL: finalURL.String()}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		decoded, err := decodeResponse(resp, responseOptions{MaxLength: maxBodyLength})
+		if err != nil {
+			return webPage{}, fmt.Errorf("decodeResponse: %w", err)
+		}
+
+		page.Content = decoded.Body
+
+		return page, nil
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes))
+	if err != nil {
+		return webPage{}, fmt.Errorf("read body: %w", err)
+	}
+
+	text, err := toUTF8(raw, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return webPage{}, fmt.Errorf("toUTF8: %w", err)
+	}
+
+	article, err := readability.FromReader(strings.NewReader(text), finalURL)
+	if err != nil || strings.TrimSpace(article.Content) == "" {
+		// not an article, fall back to the visible text of the whole page
+		page.Content, err = decodeHTML(text, "")
+		if err != nil {
+			return webPage{}, fmt.Errorf("decodeHTML: %w", err)
+		}
+
+		return page, nil
+	}
+
+	page.Title = article.Title
+	page.SiteName = article.SiteName
+	page.Byline = article.Byline
+	page.Published = article.PublishedTime
+
+	page.Content, err = htmltomarkdown.ConvertString(article.Content,
+		converter.WithDomain(finalURL.Scheme+"://"+finalURL.Host),
+	)
+	if err != nil {
+		return webPage{}, fmt.Errorf("htmltomarkdown.ConvertString: %w", err)
+	}
+
+	return page, nil
+}
+
+func formatWebPage(page webPage, content string, pageNumber, totalPages int) string {
+	var builder strings.Builder
+
+	if page.Title != "" {
+		builder.WriteString("# " + page.Title + "\n")
+	}
+
+	builder.WriteString("URL: " + page.URL + "\n")
+
+	if page.SiteName != "" {
+		builder.WriteString("Site: " + page.SiteName + "\n")
+	}
+
+	if page.Byline != "" {
+		builder.WriteString("Author: " + page.Byline + "\n")
+	}
+
+	if page.Published != nil {
+		builder.WriteString("Published: " + page.Published.Format(time.RFC3339) + "\n")
+	}
+
+	builder.WriteString(fmt.Sprintf("Page %d of %d", pageNumber, totalPages))
+
+	if pageNumber < totalPages {
+		builder.WriteString(fmt.Sprintf(", request page %d to continue", pageNumber+1))
+	}
+
+	builder.WriteString("\n\n")
+	builder.WriteString(content)
+
+	return builder.String()
+}
+
+// splitIntoPages cuts the text into pages of at most pageLength characters, preferring paragraph boundaries
+func splitIntoPages(text string, pageLength int) []string {
+	var pages []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			pages = append(pages, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraphRunes := []rune(paragraph)
+
+		// a single paragraph longer than a page is cut hard
+		for len(paragraphRunes) > pageLength {
+			flush()
+			pages = append(pages, string(paragraphRunes[:pageLength]))
+			paragraphRunes = paragraphRunes[pageLength:]
+		}
+
+		if len([]rune(current.String()))+len(paragraphRunes)+2 > pageLength {
+			flush()
+		}
+
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+
+		current.WriteString(string(paragraphRunes))
+	}
+
+	flush()
+
+	if len(pages) == 0 {
+		pages = append(pages, "")
+	}
+
+	return pages
+}
+
+func (c *WebReadCommand) Name() string {
+	return "web_read"
+}
+
+func (c *WebReadCommand) Description() string {
+	return strings.TrimSpace(`
+    type: object
+    required:
+      - command
+      - url
+    properties:
+      command:
+        type: string
+        enum:
+          - web_read
+      url:
+        type: string
+        description: The URL of the page to read, e.g. one of the web_search results
+      page:
+        type: integer
+        minimum: 1
+        default: 1
+        description: Page of the extracted content to return, long articles are split into pages
+    description: Fetches a web page, follows redirects and extracts its main content as Markdown with the title and links, without navigation, ads and scripts. Prefer it over http_request for reading articles and documentation.
+
+    RESULT SPEC:
+
+    type: string
+    description: Markdown with the title, final URL, page number and the content
+  `)
+}
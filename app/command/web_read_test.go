@@ -0,0 +1,87 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"frank/app/dto"
+	"frank/pkg/config"
+	"frank/pkg/egress"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeReplier struct{}
+
+func (f *fakeReplier) Reply(context.Context, string) {}
+
+var testArticle = `<!DOCTYPE html>
+<html><head><title>Go memory model</title><script>track()</script></head>
+<body>
+<nav><a href="/">Home</a> <a href="/blog">Blog</a></nav>
+<article>
+<h1>Go memory model</h1>
+<p>The Go memory model specifies the conditions under which reads of a variable in one goroutine
+can be guaranteed to observe values produced by writes to the same variable in a different goroutine.</p>
+<p>Programs that modify data being simultaneously accessed by multiple goroutines must serialize
+such access. To serialize access, protect the data with channel operations or other synchronization
+primitives such as those in the <a href="/pkg/sync">sync</a> and sync/atomic packages.</p>
+<p>If you must read the rest of this document to understand the behavior of your program,
+you are being too clever. Don't be clever.</p>
+</article>
+<footer>Copyright footer</footer>
+</body></html>`
+
+func TestWebReadCommand_Execute(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ref/mem", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/ref/mem", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = fmt.Fprint(w, testArticle)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Egress.Schemes = []string{"http", "https"}
+	cfg.Egress.AllowCIDRs = []string{"127.0.0.0/8"}
+
+	policy, err := egress.NewPolicy(cfg)
+	require.NoError(t, err)
+
+	command := NewWebReadCommand(&fakeReplier{}, policy)
+
+	output, err := command.Execute(context.Background(), dto.Prompt{
+		Text: `{"command": "web_read", "url": "` + server.URL + `/old"}`,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "# Go memory model")
+	assert.Contains(t, output, "URL: "+server.URL+"/ref/mem")
+	assert.Contains(t, output, "Page 1 of 1")
+	assert.Contains(t, output, "[sync]("+server.URL+"/pkg/sync)")
+	assert.NotContains(t, output, "track()")
+	assert.NotContains(t, output, "Copyright footer")
+
+	output, err = command.Execute(context.Background(), dto.Prompt{
+		Text: `{"command": "web_read", "url": "` + server.URL + `/old", "page": 3}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Error: Page 3 doesn't exist, the content has 1 pages.", output)
+}
+
+func TestSplitIntoPages(t *testing.T) {
+	paragraphs := []string{strings.Repeat("a", 4), strings.Repeat("b", 4), strings.Repeat("c", 12)}
+
+	pages := splitIntoPages(strings.Join(paragraphs, "\n\n"), 10)
+
+	assert.Equal(t, []string{"aaaa\n\nbbbb", "cccccccccc", "cc"}, pages)
+	assert.Equal(t, []string{""}, splitIntoPages("", 10))
+}
@@ -9,20 +9,33 @@ import (
 	"strings"
 )
 
+// cache statuses reported in logs
+const (
+	cacheHit    = "hit"
+	cacheMiss   = "miss"
+	cacheBypass = "bypass"
+)
+
 type WebSearchCommand struct {
 	replier      Replier
 	searchEngine WebSearchEngine
+	cache        ResultCache
 }
 
-func NewWebSearchCommand(replier Replier, searchEngine WebSearchEngine) *WebSearchCommand {
+func NewWebSearchCommand(replier Replier, searchEngine WebSearchEngine, cache ResultCache) *WebSearchCommand {
 	return &WebSearchCommand{
 		replier:      replier,
 		searchEngine: searchEngine,
+		cache:        cache,
 	}
 }
 
 type WebSearchCommandData struct {
-	Query string `json:"query"`
+	Query   string `json:"query"`
+	Format  string `json:"format,omitempty"` // text or json
+	NoCache bool   `json:"no_cache,omitempty"`
+
+	dto.WebSearchOptions
 }
 
 func (c *WebSearchCommand) Execute(ctx context.Context, prompt dto.Prompt) (string, error) {
@@ -44,14 +57,86 @@ func (c *WebSearchCommand) Execute(ctx context.Context, prompt dto.Prompt) (stri
 		return "", fmt.Errorf("empty query")
 	}
 
+	results, cacheStatus, err := c.search(ctx, requestData)
+	if err != nil {
+		return "", fmt.Errorf("WebSearch: %w", err)
+	}
+
+	logger.InfoContext(ctx, "Web search completed",
+		slog.Int("results", len(results)),
+		slog.String("cache", cacheStatus),
+	)
+
+	switch requestData.Format {
+	case "json":
+		resultJSON, err := json.Marshal(results)
+		if err != nil {
+			return "", fmt.Errorf("json marshal: %w", err)
+		}
+
+		return string(resultJSON), nil
+	case "", "text":
+		return formatSearchResults(results), nil
+	default:
+		return fmt.Sprintf("Error: Unknown format '%s', use text or json.", requestData.Format), nil
+	}
+}
+
+// search returns cached results unless the model asked for fresh ones
+func (c *WebSearchCommand) search(ctx context.Context, requestData WebSearchCommandData) ([]dto.WebSearchResult, string, error) {
+	cacheKey, err := json.Marshal([]any{requestData.Query, requestData.WebSearchOptions})
+	if err != nil {
+		return nil, "", fmt.Errorf("json marshal: %w", err)
+	}
+
+	cacheStatus := cacheBypass
+
+	if !requestData.NoCache {
+		cacheStatus = cacheMiss
+
+		if cached, ok := c.cache.Get(ctx, c.Name(), string(cacheKey)); ok {
+			var results []dto.WebSearchResult
+			if err = json.Unmarshal(cached, &results); err == nil {
+				return results, cacheHit, nil
+			}
+		}
+	}
+
 	c.replier.Reply(ctx, fmt.Sprintf("Web searching query '%s'...", requestData.Query))
 
-	result, err := c.searchEngine.WebSearch(ctx, requestData.Query)
+	results, err := c.searchEngine.WebSearch(ctx, requestData.Query, requestData.WebSearchOptions)
 	if err != nil {
-		return "", fmt.Errorf("WebSearch: %w", err)
+		return nil, cacheStatus, err
 	}
 
-	return result, nil
+	if resultsJSON, err := json.Marshal(results); err == nil {
+		c.cache.Set(ctx, c.Name(), string(cacheKey), resultsJSON)
+	}
+
+	return results, cacheStatus, nil
+}
+
+// formatSearchResults renders a compact numbered list, which takes far fewer tokens than JSON
+func formatSearchResults(results []dto.WebSearchResult) string {
+	if len(results) == 0 {
+		return "No results found."
+	}
+
+	var builder strings.Builder
+
+	for i, result := range results {
+		builder.WriteString(fmt.Sprintf("%d. %s\n   %s\n", i+1, result.Title, result.URL))
+
+		if result.Snippet != "" {
+			builder.WriteString("   " + result.Snippet + "\n")
+		}
+
+		for _, passage := range result.Passages {
+			builder.WriteString("   " + passage + "\n")
+		}
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
 }
 
 func (c *WebSearchCommand) Name() string {
@@ -72,6 +157,58 @@ func (c *WebSearchCommand) Description() string {
       query:
         type: string
         description: Search query
-    description: Executes a web search query. Returns result as an XML string.
+      page:
+        type: integer
+        minimum: 1
+        default: 1
+        description: Page of the results
+      count:
+        type: integer
+        minimum: 1
+        maximum: 100
+        default: 10
+        description: Number of results on the page
+      region:
+        type: string
+        description: Region id to localize results, used by the Yandex provider, e.g. 213 for Moscow, 2 for Saint Petersburg
+      l10n:
+        type: string
+        enum: [ru, en, uk, be, kk, tr]
+        description: Language of the search interface
+      family_mode:
+        type: string
+        enum: [none, moderate, strict]
+        default: none
+        description: Adult content filtering
+      no_cache:
+        type: boolean
+        default: false
+        description: Skip recently cached results, e.g. for news and other fresh data
+      format:
+        type: string
+        enum: [text, json]
+        default: text
+        description: Compact numbered text list or a JSON array
+    description: Executes a web search query. Use web_read to read the found pages.
+
+    RESULT SPEC:
+
+    type: array
+    description: In json format, text format contains the same fields as a numbered list
+    items:
+      type: object
+      properties:
+        title:
+          type: string
+        url:
+          type: string
+        domain:
+          type: string
+        snippet:
+          type: string
+        passages:
+          type: array
+          items:
+            type: string
   `)
 }
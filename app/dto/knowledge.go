@@ -0,0 +1,27 @@
+package dto
+
+// KnowledgeSource tells where a knowledge entry comes from
+type KnowledgeSource string
+
+const (
+	BaseKnowledgeSource     KnowledgeSource = "base"
+	ConfigKnowledgeSource   KnowledgeSource = "config"
+	FileKnowledgeSource     KnowledgeSource = "file"
+	DatabaseKnowledgeSource KnowledgeSource = "database"
+)
+
+type KnowledgeEntry struct {
+	Name    string          `json:"name"`
+	Content string          `json:"content"`
+	Source  KnowledgeSource `json:"source"`
+	Tags    []string        `json:"tags,omitempty"`
+
+	// Secrets are names of the %frank(name) secrets the entry uses
+	Secrets  []string `json:"secrets,omitempty"`
+	BaseURLs []string `json:"base_urls,omitempty"`
+	// Examples are complete commands the model can adapt
+	Examples []string `json:"examples,omitempty"`
+
+	// Path is the file the entry is loaded from, relative to the knowledge directory
+	Path string `json:"path,omitempty"`
+}
@@ -2,17 +2,30 @@ package dto
 
 import (
 	"context"
+	"frank/pkg/util"
 
 	"github.com/google/uuid"
 )
 
+// Channels identify the frontend a prompt came from and where its replies are delivered
+const (
+	TelegramChannel = "telegram"
+	ConsoleChannel  = "console"
+	APIChannel      = "api"
+	WebhookChannel  = "webhook"
+	EmailChannel    = "email"
+)
+
 type Attachment struct {
 	Name    string `json:"name"`
 	Content string `json:"content"`
+	// Original is set when Content is a summary or a page of it, the model can page through it with read_attachment
+	Original string `json:"original,omitempty"`
 }
 
 type Prompt struct {
 	ID          uuid.UUID    `json:"id"`
+	Channel     string       `json:"channel"`
 	MessageID   int          `json:"message_id"`
 	Text        string       `json:"text"`
 	Depth       int          `json:"depth"`
@@ -33,6 +46,7 @@ func (p *Prompt) BranchWithNewText(text string) Prompt {
 
 	return Prompt{
 		ID:          p.ID,
+		Channel:     p.Channel,
 		MessageID:   p.MessageID,
 		Text:        text,
 		Depth:       p.Depth + 1,
@@ -53,6 +67,7 @@ func (p *Prompt) BranchWithNewAttachment(newAttachment Attachment) Prompt {
 
 	return Prompt{
 		ID:          p.ID,
+		Channel:     p.Channel,
 		MessageID:   p.MessageID,
 		Text:        p.Text,
 		Depth:       p.Depth + 1,
@@ -66,3 +81,11 @@ func (p *Prompt) BranchWithNewAttachment(newAttachment Attachment) Prompt {
 func (p *Prompt) CancelAllBranches() {
 	p.Cancel()
 }
+
+// ContextWithPrompt stores the prompt identity in the context, so that replies can be routed to its channel
+func ContextWithPrompt(ctx context.Context, prompt Prompt) context.Context {
+	ctx = context.WithValue(ctx, util.PromptIDContextKey, prompt.ID.String())
+	ctx = context.WithValue(ctx, util.ChannelContextKey, prompt.Channel)
+
+	return ctx
+}
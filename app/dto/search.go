@@ -0,0 +1,17 @@
+package dto
+
+type WebSearchOptions struct {
+	Page       int    `json:"page,omitempty"`        // starting from 1
+	Region     string `json:"region,omitempty"`      // provider specific region id
+	L10n       string `json:"l10n,omitempty"`        // interface language: ru, en, uk, be, kk, tr
+	FamilyMode string `json:"family_mode,omitempty"` // none, moderate or strict
+	Count      int    `json:"count,omitempty"`
+}
+
+type WebSearchResult struct {
+	Title    string   `json:"title"`
+	URL      string   `json:"url"`
+	Domain   string   `json:"domain"`
+	Snippet  string   `json:"snippet,omitempty"`
+	Passages []string `json:"passages,omitempty"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+// SecretLocation is the part of an outgoing request a secret is filled into
+type SecretLocation string
+
+const (
+	HeaderSecretLocation SecretLocation = "header"
+	BodySecretLocation   SecretLocation = "body"
+	QuerySecretLocation  SecretLocation = "query"
+	PathSecretLocation   SecretLocation = "path"
+)
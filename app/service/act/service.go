@@ -4,15 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"frank/app/client/yandex"
 	"frank/app/command"
 	"frank/app/dto"
+	"frank/app/service/cache"
+	"frank/app/service/cookie_jar"
+	"frank/app/service/email"
+	"frank/app/service/knowledge"
 	"frank/app/service/reason"
+	"frank/app/service/reply"
 	"frank/app/service/scheduler"
+	"frank/app/service/search"
 	"frank/app/service/secret"
-	"frank/app/service/telegram_reply"
+	"frank/app/service/summary"
+	"frank/app/service/webhook"
 	"frank/pkg/config"
 	"frank/pkg/database"
+	"frank/pkg/egress"
 
 	"github.com/samber/do"
 )
@@ -26,6 +33,7 @@ type Command interface {
 type Service struct {
 	cfg                   *config.Config
 	queries               *database.Queries
+	secretService         *secret.Service
 	commands              []Command
 	rootDescription       string
 	additionalDescription string
@@ -33,20 +41,25 @@ type Service struct {
 
 func New(di *do.Injector) (*Service, error) {
 	cfg := do.MustInvoke[*config.Config](di)
-	yandexClient := do.MustInvoke[*yandex.Client](di)
-	replyService := do.MustInvoke[*telegram_reply.Service](di)
+	searchService := do.MustInvoke[*search.Service](di)
+	cacheService := do.MustInvoke[*cache.Service](di)
+	replyService := do.MustInvoke[*reply.Service](di)
 	schedulerService := do.MustInvoke[*scheduler.Service](di)
 	reasonService := do.MustInvoke[*reason.Service](di)
 	secretsService := do.MustInvoke[*secret.Service](di)
+	webhookService := do.MustInvoke[*webhook.Service](di)
+	emailService := do.MustInvoke[*email.Service](di)
+	knowledgeService := do.MustInvoke[*knowledge.Service](di)
 
 	actService := &Service{
-		cfg:     cfg,
-		queries: do.MustInvoke[*database.Queries](di),
+		cfg:           cfg,
+		queries:       do.MustInvoke[*database.Queries](di),
+		secretService: secretsService,
 	}
 
 	rootCommands := []Command{
 		command.NewReplyCommand(replyService),
-		command.NewAttachCommand(actService, reasonService),
+		command.NewAttachCommand(actService, reasonService, do.MustInvoke[*summary.Service](di)),
 		command.NewChainCommand(actService),
 	}
 
@@ -54,8 +67,24 @@ func New(di *do.Injector) (*Service, error) {
 		command.NewScheduleCommand(replyService, schedulerService),
 		command.NewListScheduleCommand(schedulerService),
 		command.NewCancelScheduleCommand(replyService, schedulerService),
-		command.NewHTTPRequestCommand(replyService, secretsService),
-		command.NewWebSearchCommand(replyService, yandexClient),
+		command.NewHTTPRequestCommand(
+			replyService,
+			secretsService,
+			do.MustInvoke[*egress.Policy](di),
+			do.MustInvoke[*cookie_jar.Service](di),
+			cacheService,
+		),
+		command.NewWebSearchCommand(replyService, searchService, cacheService),
+		command.NewWebReadCommand(replyService, do.MustInvoke[*egress.Policy](di)),
+		command.NewCreateWebhookCommand(replyService, webhookService),
+		command.NewRememberCommand(replyService, knowledgeService),
+		command.NewForgetCommand(replyService, knowledgeService),
+		command.NewListKnowledgeCommand(knowledgeService),
+		command.NewReadAttachmentCommand(),
+	}
+
+	if emailService.SendingEnabled() {
+		additionalCommands = append(additionalCommands, command.NewSendEmailCommand(replyService, emailService))
 	}
 
 	allCommands := make([]Command, 0, len(additionalCommands)+len(rootCommands))
@@ -102,7 +131,8 @@ func (s *Service) Handle(ctx context.Context, prompt dto.Prompt) (string, error)
 		return "", fmt.Errorf("command.Handle failed for command %s: %w", cmd.Name(), err)
 	}
 
-	return output, nil
+	// command outputs end up in attachments, so responses echoing secrets must not leak them
+	return s.secretService.Redact(output), nil
 }
 
 func (s *Service) RootCommandsDescription() string {
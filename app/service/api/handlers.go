@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CreatePromptRequest struct {
+	Text string `json:"text"`
+}
+
+type CreatePromptResponse struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (s *Service) handleCreatePrompt(c *fiber.Ctx) error {
+	var req CreatePromptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "text is empty")
+	}
+
+	newPrompt := s.promptManager.CreatePrompt(dto.APIChannel, 0, text)
+
+	// the stream must exist before the prompt starts, otherwise the first events are lost
+	s.mu.Lock()
+	s.streams[newPrompt.ID] = newPromptStream()
+	s.mu.Unlock()
+
+	slog.InfoContext(newPrompt.Ctx, "Got api prompt",
+		slog.String("text", text),
+	)
+
+	s.reasonService.Handle(newPrompt)
+
+	return c.Status(fiber.StatusAccepted).JSON(CreatePromptResponse{ //nolint:wrapcheck
+		ID: newPrompt.ID,
+	})
+}
+
+func (s *Service) handlePromptEvents(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid prompt id")
+	}
+
+	stream := s.getStream(id)
+	if stream == nil {
+		return fiber.NewError(fiber.StatusNotFound, "prompt not found")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		s.writeEvents(w, stream)
+	})
+
+	return nil
+}
+
+func (s *Service) writeEvents(w *bufio.Writer, stream *promptStream) {
+	offset := 0
+
+	for {
+		events, finished, notify := stream.since(offset)
+
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.Error("Failed to marshal prompt event",
+					slog.Any("error", err),
+				)
+				return
+			}
+
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		offset += len(events)
+
+		// flush fails once the client has disconnected
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		if finished {
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-time.After(keepAliveInterval):
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+		case <-s.appCtx.Done():
+			return
+		}
+	}
+}
+
+func (s *Service) handleCancelPrompt(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid prompt id")
+	}
+
+	if !s.promptManager.Cancel(id) {
+		return fiber.NewError(fiber.StatusNotFound, "prompt not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent) //nolint:wrapcheck
+}
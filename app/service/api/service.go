@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"frank/app/dto"
+	"frank/app/service/http_server"
+	"frank/app/service/prompt_manager"
+	"frank/app/service/reason"
+	"frank/app/service/reply"
+	"frank/pkg/config"
+	"frank/pkg/util"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/keyauth"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+)
+
+var _ reply.Replier = (*Service)(nil)
+var _ prompt_manager.Listener = (*Service)(nil)
+
+var streamRetention = 10 * time.Minute
+var keepAliveInterval = 15 * time.Second
+
+// Service exposes prompts over an authenticated HTTP JSON API with results streamed as server-sent events
+type Service struct {
+	appCtx        context.Context
+	cfg           *config.Config
+	reasonService *reason.Service
+	promptManager *prompt_manager.Service
+
+	streams map[uuid.UUID]*promptStream
+	mu      sync.Mutex
+}
+
+func New(di *do.Injector) (*Service, error) {
+	cfg := do.MustInvoke[*config.Config](di)
+
+	service := &Service{
+		appCtx:        do.MustInvoke[context.Context](di),
+		cfg:           cfg,
+		reasonService: do.MustInvoke[*reason.Service](di),
+		promptManager: do.MustInvoke[*prompt_manager.Service](di),
+		streams:       make(map[uuid.UUID]*promptStream),
+	}
+
+	if service.Enabled() {
+		app := do.MustInvoke[*http_server.Service](di).App()
+
+		group := app.Group("/api", keyauth.New(keyauth.Config{
+			Validator: service.validateToken,
+		}))
+		group.Post("/prompts", service.handleCreatePrompt)
+		group.Get("/prompts/:id/events", service.handlePromptEvents)
+		group.Delete("/prompts/:id", service.handleCancelPrompt)
+	}
+
+	return service, nil
+}
+
+// Enabled reports whether the API is exposed, which requires at least one token
+func (s *Service) Enabled() bool {
+	return len(s.cfg.API.Tokens) > 0
+}
+
+func (s *Service) validateToken(_ *fiber.Ctx, key string) (bool, error) {
+	for _, token := range s.cfg.API.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			return true, nil
+		}
+	}
+
+	return false, keyauth.ErrMissingOrMalformedAPIKey
+}
+
+func (s *Service) getStream(id uuid.UUID) *promptStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.streams[id]
+}
+
+func (s *Service) Reply(ctx context.Context, text string) {
+	idStr, _ := ctx.Value(util.PromptIDContextKey).(string)
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		slog.ErrorContext(ctx, "Reply without prompt id in api channel",
+			slog.String("text", text),
+		)
+		return
+	}
+
+	stream := s.getStream(id)
+	if stream == nil {
+		return
+	}
+
+	stream.push(Event{
+		Type: ReplyEventType,
+		Text: text,
+	})
+}
+
+func (s *Service) PromptStepStarted(prompt dto.Prompt, step int) {
+	if prompt.Channel != dto.APIChannel {
+		return
+	}
+
+	stream := s.getStream(prompt.ID)
+	if stream == nil {
+		return
+	}
+
+	stream.push(Event{
+		Type: StepEventType,
+		Step: step,
+		Text: prompt.Text,
+	})
+}
+
+func (s *Service) PromptFinished(prompt dto.Prompt, cancelled bool) {
+	if prompt.Channel != dto.APIChannel {
+		return
+	}
+
+	stream := s.getStream(prompt.ID)
+	if stream == nil {
+		return
+	}
+
+	stream.push(Event{
+		Type:   FinishedEventType,
+		Status: util.Ternary(cancelled, "cancelled", "done"),
+	})
+
+	time.AfterFunc(streamRetention, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.streams, prompt.ID)
+	})
+}
@@ -0,0 +1,57 @@
+package api
+
+import "sync"
+
+const (
+	StepEventType     = "step"
+	ReplyEventType    = "reply"
+	FinishedEventType = "finished"
+)
+
+type Event struct {
+	Type   string `json:"type"`
+	Step   int    `json:"step,omitempty"`
+	Text   string `json:"text,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// promptStream keeps all events of a single prompt, so that late subscribers receive the full history
+type promptStream struct {
+	events   []Event
+	finished bool
+	notify   chan struct{}
+	mu       sync.Mutex
+}
+
+func newPromptStream() *promptStream {
+	return &promptStream{
+		notify: make(chan struct{}),
+	}
+}
+
+func (s *promptStream) push(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return
+	}
+
+	s.events = append(s.events, event)
+	s.finished = event.Type == FinishedEventType
+
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// since returns events starting from the given offset and a channel that is closed on the next event
+func (s *promptStream) since(offset int) ([]Event, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offset > len(s.events) {
+		offset = len(s.events)
+	}
+
+	return s.events[offset:], s.finished, s.notify
+}
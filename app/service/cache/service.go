@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"frank/pkg/config"
+	"frank/pkg/database"
+	"log/slog"
+	"time"
+
+	"github.com/samber/do"
+)
+
+type store interface {
+	get(ctx context.Context, key string, now time.Time) ([]byte, bool, error)
+	set(ctx context.Context, key string, value []byte, expires time.Time) error
+}
+
+// Service caches command results for the TTL configured per command
+type Service struct {
+	cfg   *config.Config
+	store store
+	now   func() time.Time
+}
+
+func New(di *do.Injector) (*Service, error) {
+	cfg := do.MustInvoke[*config.Config](di)
+
+	service := &Service{
+		cfg: cfg,
+		now: time.Now,
+	}
+
+	switch cfg.Cache.Backend {
+	case "memory":
+		service.store = newMemoryStore()
+	case "postgres":
+		service.store = newPostgresStore(do.MustInvoke[*database.Queries](di))
+	default:
+		return nil, fmt.Errorf("unknown cache backend %s", cfg.Cache.Backend)
+	}
+
+	return service, nil
+}
+
+// Get returns the value cached for the key, which is any string identifying the request. Commands without a TTL always miss, store errors count as a miss.
+func (s *Service) Get(ctx context.Context, command, key string) ([]byte, bool) {
+	if s.ttl(command) <= 0 {
+		return nil, false
+	}
+
+	value, ok, err := s.store.get(ctx, storeKey(command, key), s.now())
+	if err != nil {
+		slog.WarnContext(ctx, "Cache read failed",
+			slog.String("command", command),
+			slog.Any("error", err),
+		)
+
+		return nil, false
+	}
+
+	return value, ok
+}
+
+func (s *Service) Set(ctx context.Context, command, key string, value []byte) {
+	ttl := s.ttl(command)
+	if ttl <= 0 {
+		return
+	}
+
+	if err := s.store.set(ctx, storeKey(command, key), value, s.now().Add(ttl)); err != nil {
+		slog.WarnContext(ctx, "Cache write failed",
+			slog.String("command", command),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (s *Service) ttl(command string) time.Duration {
+	return s.cfg.Cache.TTL[command]
+}
+
+// storeKey hashes the key, which may contain secrets and be arbitrarily long
+func storeKey(command, key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return command + ":" + hex.EncodeToString(hash[:])
+}
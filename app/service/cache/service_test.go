@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"frank/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_GetSet(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg := &config.Config{}
+	cfg.Cache.TTL = map[string]time.Duration{"web_search": time.Hour}
+
+	service := &Service{
+		cfg:   cfg,
+		store: newMemoryStore(),
+		now:   func() time.Time { return now },
+	}
+
+	ctx := context.Background()
+
+	_, ok := service.Get(ctx, "web_search", "query")
+	assert.False(t, ok)
+
+	service.Set(ctx, "web_search", "query", []byte("results"))
+
+	value, ok := service.Get(ctx, "web_search", "query")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("results"), value)
+
+	// commands without a TTL are never cached
+	service.Set(ctx, "http_request", "url", []byte("response"))
+	_, ok = service.Get(ctx, "http_request", "url")
+	assert.False(t, ok)
+
+	now = now.Add(2 * time.Hour)
+
+	_, ok = service.Get(ctx, "web_search", "query")
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_Evict(t *testing.T) {
+	defer func(previous int) { maxMemoryEntries = previous }(maxMemoryEntries)
+	maxMemoryEntries = 3
+
+	now := time.Now()
+	store := newMemoryStore()
+	ctx := context.Background()
+
+	for i := range 5 {
+		_ = store.set(ctx, fmt.Sprint(i), []byte{byte(i)}, now.Add(time.Duration(i+1)*time.Hour))
+	}
+
+	assert.Len(t, store.entries, 3)
+
+	// entries closest to expiry are evicted first
+	_, ok, _ := store.get(ctx, "0", now)
+	assert.False(t, ok)
+
+	_, ok, _ = store.get(ctx, "4", now)
+	assert.True(t, ok)
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"frank/pkg/database"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// expired entries are removed at most this often
+var cleanupInterval = time.Hour
+
+var maxMemoryEntries = 1000
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+type memoryStore struct {
+	entries map[string]memoryEntry
+	mu      sync.Mutex
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (m *memoryStore) get(_ context.Context, key string, now time.Time) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !entry.expires.After(now) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (m *memoryStore) set(_ context.Context, key string, value []byte, expires time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.entries) >= maxMemoryEntries {
+		m.evict(time.Now())
+	}
+
+	m.entries[key] = memoryEntry{value: value, expires: expires}
+
+	return nil
+}
+
+// evict drops expired entries and, if the store is still full, the ones closest to expiry
+func (m *memoryStore) evict(now time.Time) {
+	for key, entry := range m.entries {
+		if !entry.expires.After(now) {
+			delete(m.entries, key)
+		}
+	}
+
+	for len(m.entries) >= maxMemoryEntries {
+		var oldestKey string
+		var oldest time.Time
+
+		for key, entry := range m.entries {
+			if oldestKey == "" || entry.expires.Before(oldest) {
+				oldestKey, oldest = key, entry.expires
+			}
+		}
+
+		delete(m.entries, oldestKey)
+	}
+}
+
+type postgresStore struct {
+	queries     *database.Queries
+	lastCleanup time.Time
+	mu          sync.Mutex
+}
+
+func newPostgresStore(queries *database.Queries) *postgresStore {
+	return &postgresStore{queries: queries}
+}
+
+func (p *postgresStore) get(ctx context.Context, key string, now time.Time) ([]byte, bool, error) {
+	value, err := p.queries.GetCacheEntry(ctx, database.GetCacheEntryParams{
+		Key:     key,
+		Expires: now,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("GetCacheEntry: %w", err)
+	}
+
+	return value, true, nil
+}
+
+func (p *postgresStore) set(ctx context.Context, key string, value []byte, expires time.Time) error {
+	if err := p.queries.UpsertCacheEntry(ctx, database.UpsertCacheEntryParams{
+		Key:     key,
+		Value:   value,
+		Expires: expires,
+	}); err != nil {
+		return fmt.Errorf("UpsertCacheEntry: %w", err)
+	}
+
+	p.mu.Lock()
+	cleanupDue := time.Since(p.lastCleanup) > cleanupInterval
+	if cleanupDue {
+		p.lastCleanup = time.Now()
+	}
+	p.mu.Unlock()
+
+	if cleanupDue {
+		if err := p.queries.DeleteExpiredCacheEntries(ctx, time.Now()); err != nil {
+			return fmt.Errorf("DeleteExpiredCacheEntries: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"frank/app/dto"
+	"frank/app/service/prompt_manager"
+	"frank/app/service/reply"
+	"frank/pkg/util"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/samber/do"
+)
+
+var _ reply.Replier = (*Replier)(nil)
+var _ prompt_manager.Listener = (*Replier)(nil)
+
+type Replier struct {
+	out io.Writer
+	mu  sync.Mutex
+}
+
+func NewReplier(_ *do.Injector) (*Replier, error) {
+	return &Replier{
+		out: os.Stdout,
+	}, nil
+}
+
+func (r *Replier) Reply(_ context.Context, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, _ = fmt.Fprintf(r.out, "frank: %s\n", text)
+}
+
+func (r *Replier) PromptStepStarted(prompt dto.Prompt, step int) {
+	if prompt.Channel != dto.ConsoleChannel || step != 1 {
+		return
+	}
+
+	r.printReaction(prompt.MessageID, "👀")
+}
+
+func (r *Replier) PromptFinished(prompt dto.Prompt, cancelled bool) {
+	if prompt.Channel != dto.ConsoleChannel {
+		return
+	}
+
+	r.printReaction(prompt.MessageID, util.Ternary(cancelled, "🚫", "👍"))
+}
+
+func (r *Replier) printReaction(messageID int, emoji string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, _ = fmt.Fprintf(r.out, "[#%d %s]\n", messageID, emoji)
+}
@@ -0,0 +1,75 @@
+package console
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"frank/app/dto"
+	"frank/app/service/prompt_manager"
+	"frank/app/service/reason"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/samber/do"
+)
+
+var maxLineSize = 1024 * 1024
+
+// Service reads prompts from stdin, one per line, in place of the telegram bot
+type Service struct {
+	in            io.Reader
+	reasonService *reason.Service
+	promptManager *prompt_manager.Service
+}
+
+func New(di *do.Injector) (*Service, error) {
+	return &Service{
+		in:            os.Stdin,
+		reasonService: do.MustInvoke[*reason.Service](di),
+		promptManager: do.MustInvoke[*prompt_manager.Service](di),
+	}, nil
+}
+
+// Run consumes the input until EOF and then waits for the remaining prompts to finish
+func (s *Service) Run(ctx context.Context) error {
+	scanner := bufio.NewScanner(s.in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	messageID := 0
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		messageID++
+
+		switch text {
+		case "/cancel":
+			s.promptManager.CancelAll()
+		default:
+			slog.DebugContext(ctx, "Got console prompt",
+				slog.Int("message_id", messageID),
+				slog.String("text", text),
+			)
+
+			newPrompt := s.promptManager.CreatePrompt(dto.ConsoleChannel, messageID, text)
+			s.reasonService.Handle(newPrompt)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+
+	s.promptManager.Wait()
+
+	return nil
+}
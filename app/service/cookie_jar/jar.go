@@ -0,0 +1,304 @@
+package cookie_jar
+
+import (
+	"context"
+	"fmt"
+	"frank/pkg/database"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+type cookieStore interface {
+	UpsertCookie(ctx context.Context, arg database.UpsertCookieParams) error
+	DeleteCookie(ctx context.Context, arg database.DeleteCookieParams) error
+}
+
+type cookieCipher interface {
+	Seal(associated, value string) ([]byte, error)
+	Open(associated string, data []byte) (string, error)
+}
+
+type cookieKey struct {
+	domain string
+	path   string
+	name   string
+}
+
+// jar implements http.CookieJar following the RFC 6265 domain and path matching rules
+type jar struct {
+	appCtx  context.Context
+	session string
+	store   cookieStore
+	cipher  cookieCipher
+	now     func() time.Time
+
+	// values are kept decrypted in memory and encrypted on save
+	entries map[cookieKey]database.Cookie
+	mu      sync.Mutex
+}
+
+var _ http.CookieJar = (*jar)(nil)
+
+func newJar(
+	appCtx context.Context,
+	session string,
+	store cookieStore,
+	cipher cookieCipher,
+	cookies []database.Cookie,
+	now func() time.Time,
+) *jar {
+	j := &jar{
+		appCtx:  appCtx,
+		session: session,
+		store:   store,
+		cipher:  cipher,
+		now:     now,
+		entries: make(map[cookieKey]database.Cookie, len(cookies)),
+	}
+
+	for _, cookie := range cookies {
+		key := cookieKey{domain: cookie.Domain, path: cookie.Path, name: cookie.Name}
+
+		value, err := cipher.Open(j.associated(key), cookie.Value)
+		if err != nil {
+			// e.g. the master key was rotated, the site will set the cookie again after a new login
+			slog.WarnContext(appCtx, "Skipped cookie which can't be decrypted",
+				slog.String("session", session),
+				slog.String("name", cookie.Name),
+				slog.Any("error", err),
+			)
+
+			continue
+		}
+
+		cookie.Value = []byte(value)
+		j.entries[key] = cookie
+	}
+
+	return j
+}
+
+func (j *jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	host := strings.ToLower(u.Hostname())
+	now := j.now()
+
+	for _, cookie := range cookies {
+		domain, hostOnly, ok := cookieDomain(host, cookie.Domain)
+		if !ok {
+			slog.WarnContext(j.appCtx, "Rejected cookie with foreign domain",
+				slog.String("session", j.session),
+				slog.String("host", host),
+				slog.String("domain", cookie.Domain),
+			)
+
+			continue
+		}
+
+		// an insecure origin must not set or overwrite secure cookies
+		if cookie.Secure && u.Scheme != "https" {
+			continue
+		}
+
+		path := cookie.Path
+		if !strings.HasPrefix(path, "/") {
+			path = defaultPath(u.Path)
+		}
+
+		entry := database.Cookie{
+			Session:  j.session,
+			Domain:   domain,
+			Path:     path,
+			Name:     cookie.Name,
+			Value:    []byte(cookie.Value),
+			HostOnly: hostOnly,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			Updated:  now,
+		}
+
+		switch {
+		case cookie.MaxAge < 0:
+			entry.Expires = &now
+		case cookie.MaxAge > 0:
+			expires := now.Add(time.Duration(cookie.MaxAge) * time.Second)
+			entry.Expires = &expires
+		case !cookie.Expires.IsZero():
+			expires := cookie.Expires
+			entry.Expires = &expires
+		}
+
+		key := cookieKey{domain: domain, path: path, name: cookie.Name}
+
+		if entry.Expires != nil && !entry.Expires.After(now) {
+			j.delete(key)
+			continue
+		}
+
+		j.entries[key] = entry
+
+		if err := j.save(key, entry); err != nil {
+			slog.ErrorContext(j.appCtx, "Failed to save cookie",
+				slog.String("session", j.session),
+				slog.String("name", cookie.Name),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+func (j *jar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	host := strings.ToLower(u.Hostname())
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	now := j.now()
+
+	var matched []database.Cookie
+
+	for key, entry := range j.entries {
+		if entry.Expires != nil && !entry.Expires.After(now) {
+			j.delete(key)
+			continue
+		}
+
+		if entry.Secure && u.Scheme != "https" {
+			continue
+		}
+
+		if entry.HostOnly && host != entry.Domain || !entry.HostOnly && !domainMatch(host, entry.Domain) {
+			continue
+		}
+
+		if !pathMatch(path, entry.Path) {
+			continue
+		}
+
+		matched = append(matched, entry)
+	}
+
+	// longer paths go first as required by RFC 6265, names keep the order stable
+	sort.Slice(matched, func(a, b int) bool {
+		if len(matched[a].Path) != len(matched[b].Path) {
+			return len(matched[a].Path) > len(matched[b].Path)
+		}
+
+		return matched[a].Name < matched[b].Name
+	})
+
+	result := make([]*http.Cookie, 0, len(matched))
+	for _, entry := range matched {
+		result = append(result, &http.Cookie{Name: entry.Name, Value: string(entry.Value)})
+	}
+
+	return result
+}
+
+func (j *jar) save(key cookieKey, entry database.Cookie) error {
+	encrypted, err := j.cipher.Seal(j.associated(key), string(entry.Value))
+	if err != nil {
+		return fmt.Errorf("Seal: %w", err)
+	}
+
+	entry.Value = encrypted
+
+	if err = j.store.UpsertCookie(j.appCtx, database.UpsertCookieParams(entry)); err != nil {
+		return fmt.Errorf("UpsertCookie: %w", err)
+	}
+
+	return nil
+}
+
+// associated binds the encrypted value to the cookie, so that it can't be moved to another session or site
+func (j *jar) associated(key cookieKey) string {
+	return strings.Join([]string{"cookie", j.session, key.domain, key.path, key.name}, "\x00")
+}
+
+func (j *jar) delete(key cookieKey) {
+	if _, ok := j.entries[key]; !ok {
+		return
+	}
+
+	delete(j.entries, key)
+
+	if err := j.store.DeleteCookie(j.appCtx, database.DeleteCookieParams{
+		Session: j.session,
+		Domain:  key.domain,
+		Path:    key.path,
+		Name:    key.name,
+	}); err != nil {
+		slog.ErrorContext(j.appCtx, "Failed to delete cookie",
+			slog.String("session", j.session),
+			slog.String("name", key.name),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// cookieDomain returns the domain to store the cookie under and whether it is sent to that exact host only
+func cookieDomain(host, domainAttr string) (string, bool, bool) {
+	domain := strings.ToLower(strings.TrimPrefix(domainAttr, "."))
+	if domain == "" || domain == host {
+		return host, true, true
+	}
+
+	// IP addresses have no subdomains
+	if net.ParseIP(host) != nil {
+		return "", false, false
+	}
+
+	// a site must not set cookies for a whole public suffix like co.uk
+	if suffix, _ := publicsuffix.PublicSuffix(domain); suffix == domain {
+		return "", false, false
+	}
+
+	if !domainMatch(host, domain) {
+		return "", false, false
+	}
+
+	return domain, false, true
+}
+
+func domainMatch(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain) && net.ParseIP(host) == nil
+}
+
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+func defaultPath(requestPath string) string {
+	if !strings.HasPrefix(requestPath, "/") {
+		return "/"
+	}
+
+	index := strings.LastIndex(requestPath, "/")
+	if index == 0 {
+		return "/"
+	}
+
+	return requestPath[:index]
+}
@@ -0,0 +1,129 @@
+package cookie_jar
+
+import (
+	"context"
+	"fmt"
+	"frank/pkg/database"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	saved   map[string]database.UpsertCookieParams
+	deleted []string
+}
+
+func (f *fakeStore) UpsertCookie(_ context.Context, arg database.UpsertCookieParams) error {
+	f.saved[arg.Domain+arg.Path+arg.Name] = arg
+	return nil
+}
+
+func (f *fakeStore) DeleteCookie(_ context.Context, arg database.DeleteCookieParams) error {
+	delete(f.saved, arg.Domain+arg.Path+arg.Name)
+	f.deleted = append(f.deleted, arg.Name)
+	return nil
+}
+
+// fakeCipher prefixes the value with the associated data instead of encrypting it
+type fakeCipher struct{}
+
+func (fakeCipher) Seal(associated, value string) ([]byte, error) {
+	return []byte(associated + "|" + value), nil
+}
+
+func (fakeCipher) Open(associated string, data []byte) (string, error) {
+	value, ok := strings.CutPrefix(string(data), associated+"|")
+	if !ok {
+		return "", fmt.Errorf("associated data mismatch")
+	}
+
+	return value, nil
+}
+
+func cookieNames(cookies []*http.Cookie) []string {
+	names := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+
+	return names
+}
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+
+	return u
+}
+
+func TestJar(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{saved: make(map[string]database.UpsertCookieParams)}
+	j := newJar(context.Background(), "steam", store, fakeCipher{}, nil, func() time.Time { return now })
+
+	j.SetCookies(mustParse(t, "https://store.example.com/account/login"), []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/"},
+		{Name: "secure", Value: "3", Secure: true, Path: "/"},
+		{Name: "api", Value: "4", Path: "/api"},
+		{Name: "public_suffix", Value: "5", Domain: "com"},
+		{Name: "foreign", Value: "6", Domain: "attacker.com"},
+		{Name: "expiring", Value: "7", MaxAge: 60},
+	})
+
+	assert.Len(t, store.saved, 5)
+
+	tests := []struct {
+		url      string
+		expected []string
+	}{
+		{"https://store.example.com/account/profile", []string{"expiring", "host", "domain", "secure"}},
+		{"http://store.example.com/account", []string{"expiring", "host", "domain"}},
+		{"https://api.example.com/", []string{"domain"}},
+		{"https://store.example.com/api/v1", []string{"api", "domain", "secure"}},
+		{"https://store.example.com/apiv1", []string{"domain", "secure"}},
+		{"https://example.org/", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cookieNames(j.Cookies(mustParse(t, tt.url))))
+		})
+	}
+
+	t.Run("expired cookies are deleted", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+
+		assert.NotContains(t, cookieNames(j.Cookies(mustParse(t, "https://store.example.com/account/"))), "expiring")
+		assert.Contains(t, store.deleted, "expiring")
+	})
+
+	t.Run("max age below zero removes cookie", func(t *testing.T) {
+		j.SetCookies(mustParse(t, "https://store.example.com/"), []*http.Cookie{
+			{Name: "domain", Domain: "example.com", Path: "/", MaxAge: -1},
+		})
+
+		assert.Equal(t, []string{}, cookieNames(j.Cookies(mustParse(t, "https://api.example.com/"))))
+		assert.Equal(t, []string{"secure"}, cookieNames(j.Cookies(mustParse(t, "https://store.example.com/"))))
+	})
+
+	t.Run("values are saved encrypted", func(t *testing.T) {
+		saved := store.saved["store.example.com/secure"]
+		assert.Equal(t, "cookie\x00steam\x00store.example.com\x00/\x00secure|3", string(saved.Value))
+	})
+
+	t.Run("persisted cookies are loaded", func(t *testing.T) {
+		loaded := newJar(context.Background(), "steam", store, fakeCipher{}, []database.Cookie{
+			{Domain: "example.com", Path: "/", Name: "session_id", Value: []byte("cookie\x00steam\x00example.com\x00/\x00session_id|abc")},
+			{Domain: "example.com", Path: "/", Name: "moved", Value: []byte("cookie\x00other\x00example.com\x00/\x00moved|def")},
+		}, func() time.Time { return now })
+
+		assert.Equal(t, []*http.Cookie{{Name: "session_id", Value: "abc"}}, loaded.Cookies(mustParse(t, "http://www.example.com/x")))
+	})
+}
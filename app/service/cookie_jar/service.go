@@ -0,0 +1,50 @@
+package cookie_jar
+
+import (
+	"context"
+	"fmt"
+	"frank/app/service/secret"
+	"frank/pkg/database"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/samber/do"
+)
+
+var sessionRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Service provides named cookie jars persisted in the database,
+// so that logins survive between prompts and scheduled runs
+type Service struct {
+	appCtx  context.Context
+	queries *database.Queries
+	secrets *secret.Service
+}
+
+func New(di *do.Injector) (*Service, error) {
+	return &Service{
+		appCtx:  do.MustInvoke[context.Context](di),
+		queries: do.MustInvoke[*database.Queries](di),
+		secrets: do.MustInvoke[*secret.Service](di),
+	}, nil
+}
+
+// Jar loads the session cookies. Cookies set by responses are encrypted and saved right away.
+func (s *Service) Jar(ctx context.Context, session string) (http.CookieJar, error) {
+	if !sessionRegexp.MatchString(session) {
+		return nil, fmt.Errorf("invalid session name, must match %s", sessionRegexp.String())
+	}
+
+	// session cookies are credentials, they are never saved in plaintext
+	if !s.secrets.Enabled() {
+		return nil, fmt.Errorf("cookie sessions require the secret store master key")
+	}
+
+	cookies, err := s.queries.ListCookies(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("ListCookies: %w", err)
+	}
+
+	return newJar(s.appCtx, session, s.queries, s.secrets, cookies, time.Now), nil
+}
@@ -0,0 +1,123 @@
+package email
+
+import (
+	"strings"
+)
+
+// authResult is a single method result of an Authentication-Results header (RFC 8601), like dkim=pass header.d=example.com
+type authResult struct {
+	method     string
+	result     string
+	properties map[string]string
+}
+
+// parseAuthenticationResults returns the authserv-id of the header and its method results, comments are ignored
+func parseAuthenticationResults(header string) (string, []authResult) {
+	parts := strings.Split(stripComments(header), ";")
+
+	authServID := ""
+	if fields := strings.Fields(parts[0]); len(fields) > 0 {
+		authServID = strings.ToLower(fields[0])
+	}
+
+	var results []authResult
+
+	for _, part := range parts[1:] {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+
+		method, result, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue
+		}
+
+		properties := make(map[string]string)
+
+		for _, field := range fields[1:] {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				properties[strings.ToLower(key)] = strings.ToLower(strings.Trim(value, `"`))
+			}
+		}
+
+		results = append(results, authResult{
+			method:     strings.ToLower(method),
+			result:     strings.ToLower(result),
+			properties: properties,
+		})
+	}
+
+	return authServID, results
+}
+
+func stripComments(header string) string {
+	var builder strings.Builder
+
+	depth := 0
+
+	for _, r := range header {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// isAuthenticated checks that the receiving server verified the sender: DMARC passed for the From domain,
+// or DKIM or SPF passed for a domain aligned with it. Only the topmost header added by the trusted server is used,
+// headers below it could have been written by the sender.
+func isAuthenticated(headers []string, authServID, from string) bool {
+	_, fromDomain, ok := strings.Cut(from, "@")
+	if !ok || fromDomain == "" {
+		return false
+	}
+
+	for _, header := range headers {
+		id, results := parseAuthenticationResults(header)
+		if id != strings.ToLower(authServID) {
+			continue
+		}
+
+		for _, result := range results {
+			if result.result != "pass" {
+				continue
+			}
+
+			switch result.method {
+			case "dmarc":
+				if result.properties["header.from"] == fromDomain {
+					return true
+				}
+			case "dkim":
+				if isAligned(result.properties["header.d"], fromDomain) {
+					return true
+				}
+			case "spf":
+				mailFrom := result.properties["smtp.mailfrom"]
+				if _, domain, ok := strings.Cut(mailFrom, "@"); ok {
+					mailFrom = domain
+				}
+
+				if isAligned(mailFrom, fromDomain) {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	return false
+}
+
+// isAligned checks that the authenticated domain is the From domain or its subdomain
+func isAligned(domain, fromDomain string) bool {
+	return domain == fromDomain || strings.HasSuffix(domain, "."+fromDomain)
+}
@@ -0,0 +1,88 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+)
+
+var imapTimeout = time.Minute
+
+// Mailbox is the source of incoming emails
+type Mailbox interface {
+	FetchUnseen(ctx context.Context) ([]Message, error)
+}
+
+type imapMailbox struct {
+	host     string
+	mailbox  string
+	username func() string
+	password func() string
+}
+
+// FetchUnseen downloads all unseen messages, which marks them as seen on the server
+func (m *imapMailbox) FetchUnseen(ctx context.Context) ([]Message, error) {
+	c, err := client.DialTLS(m.host, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+	defer c.Logout() //nolint:errcheck
+
+	c.Timeout = imapTimeout
+
+	if err = c.Login(m.username(), m.password()); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+
+	if _, err = c.Select(m.mailbox, false); err != nil {
+		return nil, fmt.Errorf("select %s: %w", m.mailbox, err)
+	}
+
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uids...)
+
+	section := &imap.BodySectionName{}
+	fetched := make(chan *imap.Message, len(uids))
+
+	if err = c.UidFetch(seqSet, []imap.FetchItem{section.FetchItem()}, fetched); err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	result := make([]Message, 0, len(uids))
+
+	for raw := range fetched {
+		body := raw.GetBody(section)
+		if body == nil {
+			continue
+		}
+
+		msg, err := parseMessage(body)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to parse email",
+				slog.Any("uid", raw.Uid),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		result = append(result, msg)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,165 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	From      string
+	Subject   string
+	MessageID string
+	Text      string
+	// AuthenticationResults are the values of Authentication-Results headers, topmost first
+	AuthenticationResults []string
+}
+
+type OutgoingEmail struct {
+	To        []string
+	Subject   string
+	Body      string
+	InReplyTo string
+}
+
+var headerDecoder = &mime.WordDecoder{}
+
+func parseMessage(r io.Reader) (Message, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return Message{}, fmt.Errorf("mail.ReadMessage: %w", err)
+	}
+
+	from, err := mail.ParseAddress(msg.Header.Get("From"))
+	if err != nil {
+		return Message{}, fmt.Errorf("parse from address: %w", err)
+	}
+
+	subject, err := headerDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	text, err := extractText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return Message{}, fmt.Errorf("extract text: %w", err)
+	}
+
+	return Message{
+		From:      strings.ToLower(from.Address),
+		Subject:   subject,
+		MessageID: msg.Header.Get("Message-ID"),
+		Text:      strings.TrimSpace(text),
+
+		AuthenticationResults: msg.Header["Authentication-Results"],
+	}, nil
+}
+
+// extractText returns the text/plain part of the message, falling back to the first text part of any kind
+func extractText(contentType, transferEncoding string, body io.Reader) (string, error) {
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("parse content type: %w", err)
+	}
+
+	body = decodeTransferEncoding(transferEncoding, body)
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		if !strings.HasPrefix(mediaType, "text/") {
+			return "", nil
+		}
+
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", fmt.Errorf("read body: %w", err)
+		}
+
+		return string(data), nil
+	}
+
+	var fallback string
+
+	reader := multipart.NewReader(body, params["boundary"])
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("next part: %w", err)
+		}
+
+		partType := part.Header.Get("Content-Type")
+
+		text, err := extractText(partType, part.Header.Get("Content-Transfer-Encoding"), part)
+		if err != nil {
+			return "", err
+		}
+
+		if text == "" {
+			continue
+		}
+
+		if partType == "" || strings.HasPrefix(partType, "text/plain") || strings.HasPrefix(partType, "multipart/") {
+			return text, nil
+		}
+
+		if fallback == "" {
+			fallback = text
+		}
+	}
+
+	return fallback, nil
+}
+
+func decodeTransferEncoding(transferEncoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	default:
+		return body
+	}
+}
+
+func buildMessage(from string, email OutgoingEmail) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + strings.Join(email.To, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", email.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+
+	if email.InReplyTo != "" {
+		buf.WriteString("In-Reply-To: " + email.InReplyTo + "\r\n")
+		buf.WriteString("References: " + email.InReplyTo + "\r\n")
+	}
+
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	writer := quotedprintable.NewWriter(&buf)
+	if _, err := writer.Write([]byte(email.Body)); err != nil {
+		return nil, fmt.Errorf("write body: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("close body writer: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
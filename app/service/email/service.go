@@ -0,0 +1,278 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"frank/app/dto"
+	"frank/app/service/prompt_manager"
+	"frank/app/service/reason"
+	"frank/app/service/reply"
+	"frank/app/service/secret"
+	"frank/pkg/config"
+	"frank/pkg/util"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elliotchance/pie/v2"
+	"github.com/google/uuid"
+	"github.com/samber/do"
+)
+
+var _ reply.Replier = (*Service)(nil)
+var _ prompt_manager.Listener = (*Service)(nil)
+
+type Reasoner interface {
+	Handle(prompt dto.Prompt)
+}
+
+type PromptCreator interface {
+	CreatePrompt(channel string, messageID int, text string) dto.Prompt
+}
+
+// thread collects replies to an incoming email, they are sent back as a single email once the prompt finishes
+type thread struct {
+	from      string
+	subject   string
+	messageID string
+	replies   []string
+}
+
+// Service polls a mailbox for prompts and sends emails over SMTP.
+// Credentials may reference secrets with the %frank(name) syntax.
+type Service struct {
+	cfg           *config.Config
+	reasoner      Reasoner
+	promptCreator PromptCreator
+	mailbox       Mailbox
+	sender        Sender
+
+	threads map[uuid.UUID]*thread
+	mu      sync.Mutex
+}
+
+func New(di *do.Injector) (*Service, error) {
+	cfg := do.MustInvoke[*config.Config](di)
+	secretService := do.MustInvoke[*secret.Service](di)
+
+	if cfg.Email.IMAP.Host != "" && len(cfg.Email.AllowedSenders) == 0 {
+		return nil, fmt.Errorf("email allowedSenders must be set to receive prompts by email")
+	}
+
+	if cfg.Email.IMAP.Host != "" && cfg.Email.AuthServID == "" {
+		return nil, fmt.Errorf("email authServId must be set to verify senders of prompts")
+	}
+
+	if (cfg.Email.IMAP.Host != "" || cfg.Email.SMTP.Host != "") && cfg.Email.Address == "" {
+		return nil, fmt.Errorf("email address is required")
+	}
+
+	fill := func(text string) func() string {
+		return func() string {
+			return secretService.Fill(text)
+		}
+	}
+
+	return &Service{
+		cfg:           cfg,
+		reasoner:      do.MustInvoke[*reason.Service](di),
+		promptCreator: do.MustInvoke[*prompt_manager.Service](di),
+		mailbox: &imapMailbox{
+			host:     cfg.Email.IMAP.Host,
+			mailbox:  cfg.Email.IMAP.Mailbox,
+			username: fill(cfg.Email.IMAP.Username),
+			password: fill(cfg.Email.IMAP.Password),
+		},
+		sender: &smtpSender{
+			host:     cfg.Email.SMTP.Host,
+			username: fill(cfg.Email.SMTP.Username),
+			password: fill(cfg.Email.SMTP.Password),
+		},
+		threads: make(map[uuid.UUID]*thread),
+	}, nil
+}
+
+// ReceivingEnabled reports whether prompts are read from the mailbox
+func (s *Service) ReceivingEnabled() bool {
+	return s.cfg.Email.IMAP.Host != ""
+}
+
+// SendingEnabled reports whether emails can be sent
+func (s *Service) SendingEnabled() bool {
+	return s.cfg.Email.SMTP.Host != ""
+}
+
+func (s *Service) Run(ctx context.Context) {
+	if !s.ReceivingEnabled() {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Email.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) poll(ctx context.Context) {
+	messages, err := s.mailbox.FetchUnseen(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to fetch emails",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, msg := range messages {
+		s.handleMessage(ctx, msg)
+	}
+}
+
+func (s *Service) handleMessage(ctx context.Context, msg Message) {
+	allowed := pie.Any(s.cfg.Email.AllowedSenders, func(sender string) bool {
+		return strings.EqualFold(sender, msg.From)
+	})
+	if !allowed {
+		slog.WarnContext(ctx, "Got email from unexpected sender",
+			slog.String("from", msg.From),
+			slog.String("subject", msg.Subject),
+		)
+		return
+	}
+
+	// the From header is written by the sender, so it must be backed by the receiving server checks
+	if !isAuthenticated(msg.AuthenticationResults, s.cfg.Email.AuthServID, msg.From) {
+		slog.WarnContext(ctx, "Got email which failed sender authentication",
+			slog.String("from", msg.From),
+			slog.String("subject", msg.Subject),
+			slog.Any("authentication_results", msg.AuthenticationResults),
+		)
+		return
+	}
+
+	if msg.Text == "" && msg.Subject == "" {
+		return
+	}
+
+	text := fmt.Sprintf("Email from %s\nSubject: %s\n\n%s", msg.From, msg.Subject, msg.Text)
+
+	newPrompt := s.promptCreator.CreatePrompt(dto.EmailChannel, 0, text)
+
+	s.mu.Lock()
+	s.threads[newPrompt.ID] = &thread{
+		from:      msg.From,
+		subject:   msg.Subject,
+		messageID: msg.MessageID,
+		replies:   nil,
+	}
+	s.mu.Unlock()
+
+	slog.InfoContext(newPrompt.Ctx, "Got email prompt",
+		slog.String("from", msg.From),
+		slog.String("subject", msg.Subject),
+	)
+
+	s.reasoner.Handle(newPrompt)
+}
+
+func (s *Service) Reply(ctx context.Context, text string) {
+	idStr, _ := ctx.Value(util.PromptIDContextKey).(string)
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.threads[id]; ok {
+		t.replies = append(t.replies, text)
+	}
+}
+
+func (s *Service) PromptStepStarted(_ dto.Prompt, _ int) {
+}
+
+func (s *Service) PromptFinished(prompt dto.Prompt, _ bool) {
+	if prompt.Channel != dto.EmailChannel {
+		return
+	}
+
+	s.mu.Lock()
+	t, ok := s.threads[prompt.ID]
+	delete(s.threads, prompt.ID)
+	s.mu.Unlock()
+
+	if !ok || len(t.replies) == 0 {
+		return
+	}
+
+	// the prompt context is already cancelled at this point
+	ctx := context.WithoutCancel(prompt.Ctx)
+
+	subject := t.subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+
+	if err := s.sender.Send(ctx, s.cfg.Email.Address, OutgoingEmail{
+		To:        []string{t.from},
+		Subject:   subject,
+		Body:      strings.Join(t.replies, "\n\n"),
+		InReplyTo: t.messageID,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to send email reply",
+			slog.String("to", t.from),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (s *Service) SendEmail(ctx context.Context, to []string, subject, body string) error {
+	if !s.SendingEnabled() {
+		return fmt.Errorf("email sending is not configured")
+	}
+
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+
+	addresses := make([]string, 0, len(to))
+
+	for _, recipient := range to {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient '%s': %w", recipient, err)
+		}
+
+		allowed := pie.Any(s.cfg.Email.AllowedRecipients, func(allowed string) bool {
+			return strings.EqualFold(allowed, address.Address)
+		})
+		if !allowed {
+			return fmt.Errorf("recipient %s is not in allowedRecipients", address.Address)
+		}
+
+		addresses = append(addresses, address.Address)
+	}
+
+	if err := s.sender.Send(ctx, s.cfg.Email.Address, OutgoingEmail{
+		To:        addresses,
+		Subject:   subject,
+		Body:      body,
+		InReplyTo: "",
+	}); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+var smtpTimeout = time.Minute
+
+// Sender delivers outgoing emails
+type Sender interface {
+	Send(ctx context.Context, from string, email OutgoingEmail) error
+}
+
+type smtpSender struct {
+	host     string
+	username func() string
+	password func() string
+}
+
+// Send uses implicit TLS on port 465 and STARTTLS elsewhere when the server supports it
+func (s *smtpSender) Send(ctx context.Context, from string, email OutgoingEmail) error {
+	hostname, port, err := net.SplitHostPort(s.host)
+	if err != nil {
+		return fmt.Errorf("split host port: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: hostname,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	if port == "465" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", s.host)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.host)
+	}
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	c, err := smtp.NewClient(conn, hostname)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("smtp.NewClient: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && port != "465" {
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if username := s.username(); username != "" {
+		if err = c.Auth(smtp.PlainAuth("", username, s.password(), hostname)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	data, err := buildMessage(from, email)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	if err = c.Mail(from); err != nil {
+		return fmt.Errorf("mail from: %w", err)
+	}
+
+	for _, to := range email.To {
+		if err = c.Rcpt(to); err != nil {
+			return fmt.Errorf("rcpt to %s: %w", to, err)
+		}
+	}
+
+	writer, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+
+	if _, err = writer.Write(data); err != nil {
+		return fmt.Errorf("write data: %w", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("close data: %w", err)
+	}
+
+	if err = c.Quit(); err != nil {
+		return fmt.Errorf("quit: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package http_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"frank/pkg/config"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/healthcheck"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/samber/do"
+)
+
+var _ do.Shutdownable = (*Service)(nil)
+
+var shutdownTimeout = 10 * time.Second
+
+type Service struct {
+	cfg *config.Config
+	app *fiber.App
+}
+
+func New(di *do.Injector) (*Service, error) {
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ReadTimeout:           time.Minute,
+	})
+
+	app.Use(recover.New())
+	app.Use(healthcheck.New())
+
+	return &Service{
+		cfg: do.MustInvoke[*config.Config](di),
+		app: app,
+	}, nil
+}
+
+// App returns the underlying fiber app so that other services can register their routes
+func (s *Service) App() *fiber.App {
+	return s.app
+}
+
+func (s *Service) Run(ctx context.Context) {
+	slog.InfoContext(ctx, "Starting HTTP server",
+		slog.String("listen", s.cfg.HTTP.Listen),
+	)
+
+	if err := s.app.Listen(s.cfg.HTTP.Listen); err != nil && !errors.Is(err, net.ErrClosed) {
+		slog.ErrorContext(ctx, "HTTP server failed",
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (s *Service) Shutdown() error {
+	if err := s.app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+		return fmt.Errorf("app.Shutdown: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"frank/app/dto"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// common BM25 parameters: term frequency saturation and document length normalization
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// bm25Retriever ranks chunks by keyword relevance with an in-memory BM25 index.
+// It needs no external services, the index is rebuilt when the entries change.
+type bm25Retriever struct {
+	chunkSize int
+	limit     int
+
+	mu          sync.Mutex
+	fingerprint string
+	index       *bm25Index
+}
+
+type bm25Document struct {
+	match  match
+	terms  map[string]int
+	length int
+}
+
+type bm25Index struct {
+	documents []bm25Document
+	// number of documents containing the term
+	documentFrequency map[string]int
+	averageLength     float64
+}
+
+func (r *bm25Retriever) Retrieve(_ context.Context, query string, entries []dto.KnowledgeEntry) ([]match, error) {
+	index := r.getIndex(entries)
+
+	result := index.search(tokenize(query))
+	if len(result) > r.limit {
+		result = result[:r.limit]
+	}
+
+	return result, nil
+}
+
+func (r *bm25Retriever) getIndex(entries []dto.KnowledgeEntry) *bm25Index {
+	hash := sha256.New()
+	for _, entry := range entries {
+		hash.Write([]byte(entry.Name + "\x00" + strings.Join(entry.Tags, ",") + "\x00" + entry.Content + "\x00"))
+	}
+	fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.index == nil || r.fingerprint != fingerprint {
+		r.index = newBM25Index(entries, r.chunkSize)
+		r.fingerprint = fingerprint
+	}
+
+	return r.index
+}
+
+func newBM25Index(entries []dto.KnowledgeEntry, chunkSize int) *bm25Index {
+	index := &bm25Index{documentFrequency: make(map[string]int)}
+
+	var totalLength int
+
+	for _, entry := range entries {
+		for i, chunk := range chunkText(entry.Content, chunkSize) {
+			tokens := tokenize(indexedText(entry, chunk))
+
+			document := bm25Document{
+				match:  match{Name: entry.Name, Chunk: i, Content: chunk},
+				terms:  make(map[string]int),
+				length: len(tokens),
+			}
+
+			for _, token := range tokens {
+				document.terms[token]++
+			}
+
+			for term := range document.terms {
+				index.documentFrequency[term]++
+			}
+
+			totalLength += document.length
+			index.documents = append(index.documents, document)
+		}
+	}
+
+	if len(index.documents) > 0 {
+		index.averageLength = float64(totalLength) / float64(len(index.documents))
+	}
+
+	return index
+}
+
+// search returns the documents containing any of the terms, best first
+func (i *bm25Index) search(terms []string) []match {
+	uniqueTerms := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		uniqueTerms[term] = struct{}{}
+	}
+
+	total := float64(len(i.documents))
+	result := make([]match, 0)
+
+	for _, document := range i.documents {
+		var score float64
+
+		for term := range uniqueTerms {
+			frequency := float64(document.terms[term])
+			if frequency == 0 {
+				continue
+			}
+
+			documentFrequency := float64(i.documentFrequency[term])
+			idf := math.Log(1 + (total-documentFrequency+0.5)/(documentFrequency+0.5))
+			lengthRatio := float64(document.length) / i.averageLength
+
+			score += idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*(1-bm25B+bm25B*lengthRatio))
+		}
+
+		if score > 0 {
+			m := document.match
+			m.Score = score
+			result = append(result, m)
+		}
+	}
+
+	sort.SliceStable(result, func(a, b int) bool {
+		return result[a].Score > result[b].Score
+	})
+
+	return result
+}
@@ -0,0 +1,68 @@
+package knowledge
+
+import (
+	"context"
+	"frank/app/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntries = []dto.KnowledgeEntry{
+	{Name: "steam_comments_api", Content: "Get latest comments of a profile.\n\nPost a comment to a profile."},
+	{Name: "weather", Content: "Weather forecast is available at wttr.in"},
+	{Name: "family", Content: "Anna is my sister, her birthday is in May"},
+}
+
+func TestBM25Retriever(t *testing.T) {
+	retriever := &bm25Retriever{chunkSize: 40, limit: 5}
+
+	matches, err := retriever.Retrieve(context.Background(), "post a Steam comment", testEntries)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+
+	assert.Equal(t, "steam_comments_api", matches[0].Name)
+	assert.Equal(t, "Post a comment to a profile.", matches[0].Content)
+	assert.Equal(t, 1, matches[0].Chunk)
+	assert.Greater(t, matches[0].Score, matches[1].Score)
+
+	matches, err = retriever.Retrieve(context.Background(), "what's the weather tomorrow", testEntries)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "weather", matches[0].Name)
+
+	// the index follows the changes of the entries
+	changed := append([]dto.KnowledgeEntry{}, testEntries...)
+	changed[1].Content = "Use open-meteo.com"
+
+	matches, err = retriever.Retrieve(context.Background(), "open-meteo", changed)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "Use open-meteo.com", matches[0].Content)
+
+	matches, err = retriever.Retrieve(context.Background(), "nothing matches", testEntries)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestFuseRankings(t *testing.T) {
+	result := fuseRankings([][]match{
+		{
+			{Name: "a", Content: "whole a", Whole: true},
+			{Name: "b", Content: "whole b", Whole: true},
+		},
+		{
+			{Name: "b", Chunk: 0, Content: "b0"},
+			{Name: "c", Chunk: 1, Content: "c1"},
+		},
+	})
+
+	// b is ranked by both retrievers
+	require.Len(t, result, 3)
+	assert.Equal(t, "b", result[0].Name)
+	assert.True(t, result[0].Whole)
+	assert.Equal(t, "a", result[1].Name)
+
+	assert.Equal(t, []string{"whole b", "whole a", "c1"}, contents(mergeMatches(result)))
+}
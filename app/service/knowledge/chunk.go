@@ -0,0 +1,58 @@
+package knowledge
+
+import "strings"
+
+// chunkText splits the text into chunks of at most size characters. Paragraphs are kept together when they fit,
+// longer ones are split by lines and cut hard as the last resort.
+func chunkText(text string, size int) []string {
+	var chunks []string
+	var current []rune
+
+	flush := func() {
+		if chunk := strings.TrimSpace(string(current)); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current = current[:0]
+	}
+
+	appendPart := func(part []rune, separator string) {
+		if len(current) > 0 && len(current)+len(separator)+len(part) > size {
+			flush()
+		}
+
+		if len(current) > 0 {
+			current = append(current, []rune(separator)...)
+		}
+
+		current = append(current, part...)
+	}
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraphRunes := []rune(paragraph)
+
+		if len(paragraphRunes) <= size {
+			appendPart(paragraphRunes, "\n\n")
+			continue
+		}
+
+		flush()
+
+		for _, line := range strings.Split(paragraph, "\n") {
+			lineRunes := []rune(line)
+
+			for len(lineRunes) > size {
+				flush()
+				chunks = append(chunks, string(lineRunes[:size]))
+				lineRunes = lineRunes[size:]
+			}
+
+			appendPart(lineRunes, "\n")
+		}
+
+		flush()
+	}
+
+	flush()
+
+	return chunks
+}
@@ -0,0 +1,119 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"frank/app/client/bothub"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Embedder turns texts into vectors, similar texts get vectors with a high cosine similarity
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// ID identifies the embedding space, vectors with different IDs can't be compared
+	ID() string
+}
+
+// localEmbedder hashes words and their character trigrams into a fixed number of dimensions.
+// It's deterministic and needs no external service, trigrams make word forms partially match.
+type localEmbedder struct {
+	dimensions int
+}
+
+func newLocalEmbedder(dimensions int) *localEmbedder {
+	return &localEmbedder{dimensions: dimensions}
+}
+
+func (e *localEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	result := make([][]float32, 0, len(texts))
+
+	for _, text := range texts {
+		result = append(result, e.embed(text))
+	}
+
+	return result, nil
+}
+
+func (e *localEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dimensions)
+
+	for _, word := range tokenize(text) {
+		e.add(vector, word, 1)
+
+		runes := []rune("#" + word + "#")
+		for i := 0; i+3 <= len(runes); i++ {
+			e.add(vector, string(runes[i:i+3]), 0.5)
+		}
+	}
+
+	normalize(vector)
+
+	return vector
+}
+
+// add puts the feature into a bucket, the sign spreads collisions evenly around zero
+func (e *localEmbedder) add(vector []float32, feature string, weight float32) {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(feature))
+	sum := hash.Sum64()
+
+	if sum&(1<<63) != 0 {
+		weight = -weight
+	}
+
+	vector[sum%uint64(e.dimensions)] += weight
+}
+
+func (e *localEmbedder) ID() string {
+	return fmt.Sprintf("local-%d", e.dimensions)
+}
+
+type bothubEmbedder struct {
+	client *bothub.Client
+	model  string
+}
+
+func (e *bothubEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	result, err := e.client.Embed(ctx, e.model, texts)
+	if err != nil {
+		return nil, fmt.Errorf("client.Embed: %w", err)
+	}
+
+	return result, nil
+}
+
+func (e *bothubEmbedder) ID() string {
+	if e.model == "" {
+		return "bothub-" + bothub.ModelTextEmbedding3Small
+	}
+
+	return "bothub-" + e.model
+}
+
+// tokenize splits the text into lowercase words, snake_case names are split too
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func normalize(vector []float32) {
+	var sum float64
+
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+
+	if sum == 0 {
+		return
+	}
+
+	norm := float32(math.Sqrt(sum))
+
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
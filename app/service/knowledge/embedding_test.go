@@ -0,0 +1,72 @@
+package knowledge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cosine(a, b []float32) float64 {
+	var sum float64
+
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+
+	return sum
+}
+
+func TestLocalEmbedder(t *testing.T) {
+	embedder := newLocalEmbedder(512)
+
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"steam_comments_api\nGet latest comments of a steam profile",
+		"post a comment to my Steam profile",
+		"weather forecast for tomorrow in Moscow",
+		"!!!",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+
+	assert.InDelta(t, 1, cosine(vectors[0], vectors[0]), 1e-5)
+	assert.Greater(t, cosine(vectors[0], vectors[1]), 0.3)
+	assert.Less(t, cosine(vectors[0], vectors[2]), 0.2)
+	assert.True(t, isZero(vectors[3]))
+
+	again, err := embedder.Embed(context.Background(), []string{"post a comment to my Steam profile"})
+	require.NoError(t, err)
+	assert.Equal(t, vectors[1], again[0])
+}
+
+func TestChunkText(t *testing.T) {
+	text := strings.Join([]string{
+		"aaaa",
+		"bbbb",
+		strings.Repeat("c", 12) + "\ndddd",
+	}, "\n\n")
+
+	assert.Equal(t, []string{"aaaa\n\nbbbb", "cccccccccc", "cc\ndddd"}, chunkText(text, 10))
+	assert.Empty(t, chunkText("  ", 10))
+}
+
+func contents(matches []match) []string {
+	result := make([]string, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.Content)
+	}
+
+	return result
+}
+
+func TestMergeMatches(t *testing.T) {
+	result := contents(mergeMatches([]match{
+		{Name: "b", Chunk: 1, Content: "b1", Score: 0.9},
+		{Name: "a", Chunk: 0, Content: "a0", Score: 0.8},
+		{Name: "b", Chunk: 0, Content: "b0", Score: 0.5},
+	}))
+
+	assert.Equal(t, []string{"b0\n\nb1", "a0"}, result)
+}
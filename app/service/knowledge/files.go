@@ -0,0 +1,178 @@
+package knowledge
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"frank/app/dto"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var frontMatterDelimiter = []byte("---")
+
+// fileEntry is the front-matter of a Markdown file or the whole YAML file
+type fileEntry struct {
+	Name     string   `yaml:"name"`
+	Tags     []string `yaml:"tags"`
+	Content  string   `yaml:"content"`
+	Secrets  []string `yaml:"secrets"`
+	BaseURLs []string `yaml:"baseUrls"`
+	Examples []string `yaml:"examples"`
+}
+
+// loadDirectory reads entries from .md, .yaml and .yml files under the root. Entry names are prefixed
+// with the directory of the file, so that files of different teams don't clash.
+func loadDirectory(root string) (map[string]dto.KnowledgeEntry, error) {
+	entries := make(map[string]dto.KnowledgeEntry)
+
+	err := walkKnowledgeFiles(root, func(filePath string, _ fs.FileInfo) error {
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return fmt.Errorf("filepath.Rel: %w", err)
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("os.ReadFile: %w", err)
+		}
+
+		entry, err := parseKnowledgeFile(relativePath, data)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", relativePath, err)
+		}
+
+		if existing, ok := entries[entry.Name]; ok {
+			return fmt.Errorf("entry %s is defined in both %s and %s", entry.Name, existing.Path, relativePath)
+		}
+
+		entries[entry.Name] = entry
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// directoryFingerprint changes whenever a knowledge file is added, removed or modified
+func directoryFingerprint(root string) (string, error) {
+	hash := sha256.New()
+
+	err := walkKnowledgeFiles(root, func(filePath string, info fs.FileInfo) error {
+		_, _ = fmt.Fprintf(hash, "%s\x00%d\x00%d\x00", filePath, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// walkKnowledgeFiles calls fn for every knowledge file in lexical order, hidden files and directories like .git are skipped
+func walkKnowledgeFiles(root string, fn func(filePath string, info fs.FileInfo) error) error {
+	err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filePath != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(filePath)) {
+		case ".md", ".yaml", ".yml":
+		default:
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("d.Info: %w", err)
+		}
+
+		return fn(filePath, info)
+	})
+	if err != nil {
+		return fmt.Errorf("walk %s: %w", root, err)
+	}
+
+	return nil
+}
+
+func parseKnowledgeFile(relativePath string, data []byte) (dto.KnowledgeEntry, error) {
+	var parsed fileEntry
+
+	if strings.EqualFold(path.Ext(relativePath), ".md") {
+		frontMatter, body := splitFrontMatter(data)
+
+		if err := yaml.Unmarshal(frontMatter, &parsed); err != nil {
+			return dto.KnowledgeEntry{}, fmt.Errorf("front-matter yaml unmarshal: %w", err)
+		}
+
+		parsed.Content = string(body)
+	} else if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return dto.KnowledgeEntry{}, fmt.Errorf("yaml unmarshal: %w", err)
+	}
+
+	name := parsed.Name
+	if name == "" {
+		name = strings.TrimSuffix(path.Base(relativePath), path.Ext(relativePath))
+	}
+
+	if !nameRegexp.MatchString(name) {
+		return dto.KnowledgeEntry{}, fmt.Errorf("invalid entry name %q, must match %s", name, nameRegexp.String())
+	}
+
+	if namespace := path.Dir(relativePath); namespace != "." {
+		name = namespace + "/" + name
+	}
+
+	return dto.KnowledgeEntry{
+		Name:     name,
+		Content:  strings.TrimSpace(parsed.Content),
+		Source:   dto.FileKnowledgeSource,
+		Tags:     parsed.Tags,
+		Path:     relativePath,
+		Secrets:  parsed.Secrets,
+		BaseURLs: parsed.BaseURLs,
+		Examples: parsed.Examples,
+	}, nil
+}
+
+// splitFrontMatter separates the YAML block enclosed in --- lines at the beginning of a Markdown file
+func splitFrontMatter(data []byte) ([]byte, []byte) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if len(lines) == 0 || !bytes.Equal(bytes.TrimSpace(lines[0]), frontMatterDelimiter) {
+		return nil, data
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if bytes.Equal(bytes.TrimSpace(lines[i]), frontMatterDelimiter) {
+			return bytes.Join(lines[1:i], nil), bytes.Join(lines[i+1:], nil)
+		}
+	}
+
+	// not closed, so it's not front-matter
+	return nil, data
+}
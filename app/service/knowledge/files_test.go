@@ -0,0 +1,102 @@
+package knowledge
+
+import (
+	"frank/app/dto"
+	"frank/pkg/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+
+	filePath := filepath.Join(root, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+}
+
+func TestLoadDirectory(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile(t, root, "games/steam.md", "---\nname: steam_comments_api\ntags: [steam, comments]\n---\n# Steam\n\nGet comments\n")
+	writeFile(t, root, "weather.yaml", "tags: [forecast]\ncontent: Use wttr.in\n")
+	writeFile(t, root, "notes.md", "No front-matter\n---\nhere")
+	writeFile(t, root, ".git/config.yaml", "content: ignored\n")
+	writeFile(t, root, "readme.txt", "ignored")
+
+	entries, err := loadDirectory(root)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]dto.KnowledgeEntry{
+		"games/steam_comments_api": {
+			Name:    "games/steam_comments_api",
+			Content: "# Steam\n\nGet comments",
+			Source:  dto.FileKnowledgeSource,
+			Tags:    []string{"steam", "comments"},
+			Path:    "games/steam.md",
+		},
+		"weather": {
+			Name:    "weather",
+			Content: "Use wttr.in",
+			Source:  dto.FileKnowledgeSource,
+			Tags:    []string{"forecast"},
+			Path:    "weather.yaml",
+		},
+		"notes": {
+			Name:    "notes",
+			Content: "No front-matter\n---\nhere",
+			Source:  dto.FileKnowledgeSource,
+			Path:    "notes.md",
+		},
+	}, entries)
+
+	writeFile(t, root, "weather.md", "---\nname: weather\n---\nduplicate")
+
+	_, err = loadDirectory(root)
+	assert.ErrorContains(t, err, "entry weather is defined in both weather.md and weather.yaml")
+
+	writeFile(t, root, "weather.md", "---\nname: bad name\n---\n")
+
+	_, err = loadDirectory(root)
+	assert.ErrorContains(t, err, "invalid entry name")
+}
+
+func TestService_ReloadFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.md", "first")
+
+	cfg := &config.Config{}
+	cfg.KnowledgeDir.Path = root
+
+	service := &Service{cfg: cfg, files: make(map[string]dto.KnowledgeEntry)}
+
+	reloaded, err := service.reloadFiles()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "first", service.files["a"].Content)
+
+	reloaded, err = service.reloadFiles()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	writeFile(t, root, "a.md", "second")
+	// modification time resolution of some filesystems is coarse
+	require.NoError(t, os.Chtimes(filepath.Join(root, "a.md"), time.Now(), time.Now().Add(time.Minute)))
+
+	reloaded, err = service.reloadFiles()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "second", service.files["a"].Content)
+
+	// broken files keep the previous entries
+	writeFile(t, root, "b.yaml", "content: [")
+
+	_, err = service.reloadFiles()
+	assert.Error(t, err)
+	assert.Equal(t, "second", service.files["a"].Content)
+}
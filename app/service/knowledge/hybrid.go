@@ -0,0 +1,91 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"frank/app/dto"
+	"sort"
+)
+
+// rrfK dampens the advantage of the top ranks in reciprocal rank fusion
+const rrfK = 60
+
+// hybridRetriever combines rankings of several retrievers with reciprocal rank fusion,
+// scores of different retrievers aren't comparable, while ranks are
+type hybridRetriever struct {
+	retrievers []retriever
+	limit      int
+}
+
+func (r *hybridRetriever) Retrieve(ctx context.Context, query string, entries []dto.KnowledgeEntry) ([]match, error) {
+	rankings := make([][]match, 0, len(r.retrievers))
+
+	for _, retriever := range r.retrievers {
+		matches, err := retriever.Retrieve(ctx, query, entries)
+		if err != nil {
+			return nil, fmt.Errorf("retriever.Retrieve: %w", err)
+		}
+
+		rankings = append(rankings, matches)
+	}
+
+	result := fuseRankings(rankings)
+	if len(result) > r.limit {
+		result = result[:r.limit]
+	}
+
+	return result, nil
+}
+
+// fuseRankings sums reciprocal ranks of the same chunks. Chunks of an entry matched as a whole
+// by another retriever count towards the whole entry.
+func fuseRankings(rankings [][]match) []match {
+	type key struct {
+		name  string
+		chunk int
+	}
+
+	wholeEntries := make(map[string]match)
+
+	for _, ranking := range rankings {
+		for _, m := range ranking {
+			if m.Whole {
+				wholeEntries[m.Name] = m
+			}
+		}
+	}
+
+	fused := make(map[key]*match)
+	order := make([]key, 0)
+
+	for _, ranking := range rankings {
+		for rank, m := range ranking {
+			k := key{name: m.Name, chunk: m.Chunk}
+
+			if whole, ok := wholeEntries[m.Name]; ok {
+				k.chunk = -1
+				m = whole
+			}
+
+			if _, ok := fused[k]; !ok {
+				fusedMatch := m
+				fusedMatch.Score = 0
+				fused[k] = &fusedMatch
+				order = append(order, k)
+			}
+
+			fused[k].Score += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	result := make([]match, 0, len(order))
+	for _, k := range order {
+		result = append(result, *fused[k])
+	}
+
+	sort.SliceStable(result, func(a, b int) bool {
+		return result[a].Score > result[b].Score
+	})
+
+	return result
+}
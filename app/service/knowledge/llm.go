@@ -0,0 +1,78 @@
+package knowledge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/client/bothub"
+	"frank/app/dto"
+	"log/slog"
+	"strings"
+
+	"github.com/elliotchance/pie/v2"
+)
+
+// llmRetriever asks the model to pick relevant entries by their names
+type llmRetriever struct {
+	bothubClient *bothub.Client
+}
+
+type ReasonResult struct {
+	Result []string `json:"result"`
+}
+
+func (r *llmRetriever) Retrieve(ctx context.Context, query string, entries []dto.KnowledgeEntry) ([]match, error) {
+	systemPrompt := generateSystemPrompt(entries)
+
+	reasonOutput, err := r.bothubClient.Process(ctx, bothub.Prompt{
+		SystemText: systemPrompt,
+		UserText:   query,
+		Model:      bothub.ModelDeepseekChatV3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gptClient.Process: %w", err)
+	}
+
+	reasonOutput = strings.TrimSpace(reasonOutput)
+	reasonOutput = strings.TrimPrefix(reasonOutput, "```json")
+	reasonOutput = strings.Trim(reasonOutput, "`")
+
+	slog.Info("Got a result from bothub client",
+		slog.String("text", query),
+		slog.Any("output", reasonOutput),
+	)
+
+	var reasonResult ReasonResult
+
+	if err = json.Unmarshal([]byte(reasonOutput), &reasonResult); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	result := make([]match, 0)
+
+	for _, entry := range entries {
+		if pie.Contains(reasonResult.Result, entry.Name) {
+			result = append(result, match{Name: entry.Name, Content: entry.Content, Score: 1, Whole: true})
+		}
+	}
+
+	return result, nil
+}
+
+func generateSystemPrompt(entries []dto.KnowledgeEntry) string {
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if len(entry.Tags) > 0 {
+			names = append(names, entry.Name+" (tags: "+strings.Join(entry.Tags, " ")+")")
+		} else {
+			names = append(names, entry.Name)
+		}
+	}
+
+	result := systemPromptTemplate
+
+	result = strings.ReplaceAll(result, "{names}", strings.Join(names, ", "))
+
+	return result
+}
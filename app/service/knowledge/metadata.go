@@ -0,0 +1,90 @@
+package knowledge
+
+import (
+	"frank/app/dto"
+	"frank/pkg/config"
+	"slices"
+	"strings"
+)
+
+func newEntry(name string, entry config.KnowledgeEntry, source dto.KnowledgeSource) dto.KnowledgeEntry {
+	return dto.KnowledgeEntry{
+		Name:     name,
+		Content:  entry.Content,
+		Source:   source,
+		Secrets:  entry.Secrets,
+		BaseURLs: entry.BaseURLs,
+		Examples: entry.Examples,
+	}
+}
+
+// missingSecrets returns the secrets declared by the entry which are neither in config nor in the secret store
+func (s *Service) missingSecrets(entry dto.KnowledgeEntry) []string {
+	if len(entry.Secrets) == 0 {
+		return nil
+	}
+
+	names := s.secretService.Names()
+
+	return slices.DeleteFunc(slices.Clone(entry.Secrets), func(name string) bool {
+		return slices.Contains(names, name)
+	})
+}
+
+// renderEntries appends the metadata to the selected contents, so that recipes can be executed as is
+func (s *Service) renderEntries(entries []dto.KnowledgeEntry, matches []match) []string {
+	result := make([]string, 0, len(matches))
+
+	for _, m := range matches {
+		idx := slices.IndexFunc(entries, func(entry dto.KnowledgeEntry) bool {
+			return entry.Name == m.Name
+		})
+		if idx < 0 {
+			result = append(result, m.Content)
+			continue
+		}
+
+		result = append(result, s.renderEntry(entries[idx], m.Content))
+	}
+
+	return result
+}
+
+func (s *Service) renderEntry(entry dto.KnowledgeEntry, content string) string {
+	if len(entry.Secrets) == 0 && len(entry.BaseURLs) == 0 && len(entry.Examples) == 0 {
+		return content
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("Knowledge entry " + entry.Name + ":\n")
+	builder.WriteString(strings.TrimSpace(content))
+	builder.WriteString("\n")
+
+	if len(entry.Secrets) > 0 {
+		references := make([]string, 0, len(entry.Secrets))
+		for _, name := range entry.Secrets {
+			references = append(references, "%frank("+name+")")
+		}
+
+		builder.WriteString("  Secrets: " + strings.Join(references, ", ") + "\n")
+
+		if missing := s.missingSecrets(entry); len(missing) > 0 {
+			builder.WriteString("  Not set yet, ask the user to set them with '/secret set <name> <value>': " + strings.Join(missing, ", ") + "\n")
+		}
+	}
+
+	if len(entry.BaseURLs) > 0 {
+		builder.WriteString("  Base URLs: " + strings.Join(entry.BaseURLs, ", ") + "\n")
+	}
+
+	if len(entry.Examples) > 0 {
+		builder.WriteString("  Example commands:\n")
+
+		for _, example := range entry.Examples {
+			builder.WriteString("    " + strings.TrimSpace(example) + "\n")
+		}
+	}
+
+	return strings.TrimSpace(builder.String())
+}
@@ -0,0 +1,71 @@
+package knowledge
+
+import (
+	"context"
+	"frank/app/dto"
+	"frank/app/service/secret"
+	"frank/pkg/config"
+	"frank/pkg/database"
+	"testing"
+
+	"github.com/samber/do"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestKnowledgeEntryYAML(t *testing.T) {
+	var entries map[string]config.KnowledgeEntry
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+plain: Just the content
+recipe:
+  content: Call the API
+  secrets: [api_token]
+  baseUrls: [https://api.example.com]
+  examples:
+    - '{"command": "http_request", "url": "https://api.example.com/items"}'
+`), &entries))
+
+	assert.Equal(t, config.KnowledgeEntry{Content: "Just the content"}, entries["plain"])
+	assert.Equal(t, config.KnowledgeEntry{
+		Content:  "Call the API",
+		Secrets:  []string{"api_token"},
+		BaseURLs: []string{"https://api.example.com"},
+		Examples: []string{`{"command": "http_request", "url": "https://api.example.com/items"}`},
+	}, entries["recipe"])
+}
+
+func TestService_RenderEntry(t *testing.T) {
+	t.Setenv("FRANK_MASTER_KEY", "")
+
+	cfg := &config.Config{Secrets: map[string]string{"api_token": "token"}}
+
+	di := do.New()
+	do.ProvideValue(di, context.Background())
+	do.ProvideValue(di, cfg)
+	do.ProvideValue(di, database.New(nil))
+
+	secretService, err := secret.New(di)
+	require.NoError(t, err)
+
+	service := &Service{cfg: cfg, secretService: secretService}
+
+	entry := newEntry("recipe", config.KnowledgeEntry{
+		Content:  "Call the API",
+		Secrets:  []string{"api_token", "api_user"},
+		BaseURLs: []string{"https://api.example.com"},
+		Examples: []string{`{"command": "http_request", "url": "https://api.example.com/items"}`},
+	}, dto.ConfigKnowledgeSource)
+
+	assert.Equal(t, []string{"api_user"}, service.missingSecrets(entry))
+	assert.Equal(t, `Knowledge entry recipe:
+Call the API
+  Secrets: %frank(api_token), %frank(api_user)
+  Not set yet, ask the user to set them with '/secret set <name> <value>': api_user
+  Base URLs: https://api.example.com
+  Example commands:
+    {"command": "http_request", "url": "https://api.example.com/items"}`, service.renderEntry(entry, entry.Content))
+
+	assert.Equal(t, "plain", service.renderEntry(dto.KnowledgeEntry{Name: "plain"}, "plain"))
+}
@@ -0,0 +1,92 @@
+package knowledge
+
+import (
+	"context"
+	"frank/app/dto"
+	"sort"
+	"strings"
+
+	"github.com/elliotchance/pie/v2"
+)
+
+// match is a chunk of a knowledge entry selected for a prompt
+type match struct {
+	Name    string
+	Chunk   int
+	Content string
+	Score   float64
+	// Whole is set when Content is the entire entry rather than a chunk
+	Whole bool
+}
+
+// retriever selects the parts of the entries relevant to the query, best matches first
+type retriever interface {
+	Retrieve(ctx context.Context, query string, entries []dto.KnowledgeEntry) ([]match, error)
+}
+
+// indexedText is what a chunk is matched by, the name and the tags often say what the entry is about
+func indexedText(entry dto.KnowledgeEntry, chunk string) string {
+	header := entry.Name
+
+	if len(entry.Tags) > 0 {
+		header += " " + strings.Join(entry.Tags, " ")
+	}
+
+	return header + "\n" + chunk
+}
+
+// mergeMatches joins the chunks of every entry in their original order, entries with better matches go first.
+// An entry matched as a whole already contains all of its chunks.
+func mergeMatches(matches []match) []match {
+	order := make([]string, 0)
+	chunks := make(map[string][]match)
+
+	for _, m := range matches {
+		if _, ok := chunks[m.Name]; !ok {
+			order = append(order, m.Name)
+		}
+
+		chunks[m.Name] = append(chunks[m.Name], m)
+	}
+
+	result := make([]match, 0, len(order))
+
+	for _, name := range order {
+		entryChunks := chunks[name]
+
+		if whole := pie.Filter(entryChunks, func(m match) bool { return m.Whole }); len(whole) > 0 {
+			result = append(result, whole[0])
+			continue
+		}
+
+		// matches are sorted best first
+		score := entryChunks[0].Score
+
+		sort.Slice(entryChunks, func(i, j int) bool {
+			return entryChunks[i].Chunk < entryChunks[j].Chunk
+		})
+
+		contents := make([]string, 0, len(entryChunks))
+		for _, m := range entryChunks {
+			contents = append(contents, m.Content)
+		}
+
+		result = append(result, match{
+			Name:    name,
+			Content: strings.Join(contents, "\n\n"),
+			Score:   score,
+		})
+	}
+
+	return result
+}
+
+func matchNames(matches []match) map[string]struct{} {
+	names := make(map[string]struct{}, len(matches))
+
+	for _, m := range matches {
+		names[m.Name] = struct{}{}
+	}
+
+	return names
+}
@@ -2,18 +2,24 @@ package knowledge
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"frank/app/client/bothub"
 	"frank/app/dto"
+	"frank/app/service/secret"
 	"frank/pkg/config"
 	"frank/pkg/database"
 	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	_ "embed"
 
 	"github.com/elliotchance/pie/v2"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/samber/do"
 	"gopkg.in/yaml.v3"
 )
@@ -24,88 +30,381 @@ var systemPromptTemplate string
 //go:embed base_knowledge.yaml
 var baseKnowledgeString string
 
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+var maxContentLength = 10000
+
+// Service selects knowledge entries relevant to a prompt. Entries come from the embedded base knowledge,
+// from config, from the knowledge directory and from the database, where the model saves them with the remember command.
 type Service struct {
 	appCtx        context.Context
 	cfg           *config.Config
 	queries       *database.Queries
 	bothubClient  *bothub.Client
-	knowledgeBase map[string]string
+	secretService *secret.Service
+	knowledgeBase map[string]dto.KnowledgeEntry
+	retriever     retriever
+	reranker      retriever
+
+	files            map[string]dto.KnowledgeEntry
+	filesFingerprint string
+	filesMu          sync.RWMutex
 }
 
 func New(di *do.Injector) (*Service, error) {
 	cfg := do.MustInvoke[*config.Config](di)
 
-	knowledgeBase := make(map[string]string)
+	baseKnowledge := make(map[string]config.KnowledgeEntry)
 
-	if err := yaml.Unmarshal([]byte(baseKnowledgeString), &knowledgeBase); err != nil {
+	if err := yaml.Unmarshal([]byte(baseKnowledgeString), &baseKnowledge); err != nil {
 		return nil, fmt.Errorf("yaml unmarshal: %w", err)
 	}
 
-	for name, content := range cfg.Knowledge {
-		knowledgeBase[name] = content
+	knowledgeBase := make(map[string]dto.KnowledgeEntry, len(baseKnowledge)+len(cfg.Knowledge))
+
+	for name, entry := range baseKnowledge {
+		knowledgeBase[name] = newEntry(name, entry, dto.BaseKnowledgeSource)
+	}
+
+	for name, entry := range cfg.Knowledge {
+		knowledgeBase[name] = newEntry(name, entry, dto.ConfigKnowledgeSource)
 	}
 
-	return &Service{
+	service := &Service{
 		appCtx:        do.MustInvoke[context.Context](di),
 		cfg:           cfg,
 		queries:       do.MustInvoke[*database.Queries](di),
 		bothubClient:  do.MustInvoke[*bothub.Client](di),
+		secretService: do.MustInvoke[*secret.Service](di),
 		knowledgeBase: knowledgeBase,
-	}, nil
+		files:         make(map[string]dto.KnowledgeEntry),
+	}
+
+	// config entries are written along with the secrets, so a mismatch is a configuration error.
+	// Base and file recipes are shared, a deployment might not use some of them.
+	for _, entry := range knowledgeBase {
+		missing := service.missingSecrets(entry)
+		if len(missing) == 0 {
+			continue
+		}
+
+		if entry.Source == dto.ConfigKnowledgeSource {
+			return nil, fmt.Errorf("knowledge entry %s requires unknown secrets: %s", entry.Name, strings.Join(missing, ", "))
+		}
+
+		slog.Warn("Knowledge entry requires secrets which are not set",
+			slog.String("name", entry.Name),
+			slog.Any("secrets", missing),
+		)
+	}
+
+	if cfg.KnowledgeDir.Path != "" {
+		if _, err := service.reloadFiles(); err != nil {
+			return nil, fmt.Errorf("reloadFiles: %w", err)
+		}
+	}
+
+	selector := &llmRetriever{bothubClient: service.bothubClient}
+
+	var primary retriever
+
+	switch cfg.KnowledgeRetrieval.Strategy {
+	case "vector":
+		primary = &vectorRetriever{
+			dbConn:    do.MustInvoke[*pgxpool.Pool](di),
+			queries:   service.queries,
+			embedder:  service.newEmbedder(),
+			chunkSize: cfg.KnowledgeRetrieval.ChunkSize,
+			threshold: *cfg.KnowledgeRetrieval.Threshold,
+			limit:     cfg.KnowledgeRetrieval.Limit,
+		}
+	case "bm25":
+		primary = service.newBM25Retriever()
+	default:
+		primary = selector
+	}
+
+	service.retriever = primary
+
+	if cfg.KnowledgeRetrieval.Hybrid && cfg.KnowledgeRetrieval.Strategy != "bm25" {
+		service.retriever = &hybridRetriever{
+			retrievers: []retriever{primary, service.newBM25Retriever()},
+			limit:      cfg.KnowledgeRetrieval.Limit,
+		}
+	}
+
+	if cfg.KnowledgeRetrieval.Rerank && cfg.KnowledgeRetrieval.Strategy != "llm" {
+		service.reranker = selector
+	}
+
+	return service, nil
 }
 
-type ReasonResult struct {
-	Result []string `json:"result"`
+func (s *Service) newBM25Retriever() *bm25Retriever {
+	return &bm25Retriever{
+		chunkSize: s.cfg.KnowledgeRetrieval.ChunkSize,
+		limit:     s.cfg.KnowledgeRetrieval.Limit,
+	}
+}
+
+func (s *Service) newEmbedder() Embedder {
+	embeddings := s.cfg.KnowledgeRetrieval.Embeddings
+
+	if embeddings.Provider == "bothub" {
+		return &bothubEmbedder{client: s.bothubClient, model: embeddings.Model}
+	}
+
+	return newLocalEmbedder(embeddings.Dimensions)
 }
 
 func (s *Service) GetRelevant(ctx context.Context, prompt dto.Prompt) ([]string, error) {
-	systemPrompt := s.generateSystemPrompt()
+	// entries are loaded on every prompt, so that remembered ones are picked up immediately
+	entries, err := s.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("List: %w", err)
+	}
 
-	reasonOutput, err := s.bothubClient.Process(ctx, bothub.Prompt{
-		SystemText: systemPrompt,
-		UserText:   prompt.Text,
-		Model:      bothub.ModelDeepseekChatV3,
-	})
+	if len(entries) == 0 {
+		return []string{}, nil
+	}
+
+	query := s.secretService.Redact(prompt.Text)
+
+	matches, err := s.retriever.Retrieve(ctx, query, entries)
 	if err != nil {
-		return nil, fmt.Errorf("gptClient.Process: %w", err)
+		return nil, fmt.Errorf("retriever.Retrieve: %w", err)
 	}
 
-	reasonOutput = strings.TrimSpace(reasonOutput)
-	reasonOutput = strings.TrimPrefix(reasonOutput, "```json")
-	reasonOutput = strings.Trim(reasonOutput, "`")
+	if s.reranker != nil && len(matches) > 0 {
+		if matches, err = s.rerank(ctx, query, entries, matches); err != nil {
+			return nil, fmt.Errorf("rerank: %w", err)
+		}
+	}
 
-	slog.Info("Got a result from bothub client",
-		slog.String("text", prompt.Text),
-		slog.Any("output", reasonOutput),
+	slog.InfoContext(ctx, "Selected relevant knowledge",
+		slog.String("prompt_id", prompt.ID.String()),
+		slog.String("strategy", s.cfg.KnowledgeRetrieval.Strategy),
+		slog.Any("names", pie.Sort(pie.Keys(matchNames(matches)))),
 	)
 
-	var reasonResult ReasonResult
+	return s.renderEntries(entries, mergeMatches(matches)), nil
+}
+
+// rerank keeps the matches of the entries the reranker selects among the matched ones
+func (s *Service) rerank(ctx context.Context, query string, entries []dto.KnowledgeEntry, matches []match) ([]match, error) {
+	names := matchNames(matches)
 
-	if err = json.Unmarshal([]byte(reasonOutput), &reasonResult); err != nil {
-		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	candidates := pie.Filter(entries, func(entry dto.KnowledgeEntry) bool {
+		_, ok := names[entry.Name]
+		return ok
+	})
+
+	selected, err := s.reranker.Retrieve(ctx, query, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("reranker.Retrieve: %w", err)
+	}
+
+	selectedNames := matchNames(selected)
+
+	return pie.Filter(matches, func(m match) bool {
+		_, ok := selectedNames[m.Name]
+		return ok
+	}), nil
+}
+
+// List returns all knowledge entries sorted by name
+func (s *Service) List(ctx context.Context) ([]dto.KnowledgeEntry, error) {
+	stored, err := s.queries.ListKnowledge(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ListKnowledge: %w", err)
 	}
 
-	result := make([]string, 0)
+	s.filesMu.RLock()
+	defer s.filesMu.RUnlock()
+
+	entries := make([]dto.KnowledgeEntry, 0, len(s.knowledgeBase)+len(s.files)+len(stored))
+
+	for _, entry := range s.knowledgeBase {
+		entries = append(entries, entry)
+	}
 
-	for name, content := range s.knowledgeBase {
-		if pie.Contains(reasonResult.Result, name) {
-			result = append(result, content)
+	for _, entry := range s.files {
+		if _, ok := s.knowledgeBase[entry.Name]; ok {
+			continue
 		}
+
+		entries = append(entries, entry)
 	}
 
-	return result, nil
+	for _, entry := range stored {
+		// entries can't shadow read-only ones, but might have been saved before the config or the files changed
+		if _, ok := s.readOnlyEntry(entry.Name); ok {
+			continue
+		}
+
+		entries = append(entries, dto.KnowledgeEntry{
+			Name:    entry.Name,
+			Content: entry.Content,
+			Source:  dto.DatabaseKnowledgeSource,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
+// Remember saves an entry to the database, replacing the one with the same name.
+// Base, config and file entries can't be overridden.
+func (s *Service) Remember(ctx context.Context, name, content string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid entry name, must match %s", nameRegexp.String())
+	}
+
+	if entry, ok := s.lockedReadOnlyEntry(name); ok {
+		return fmt.Errorf("entry %s is defined in %s knowledge and can't be changed", name, entry.Source)
+	}
+
+	content = strings.TrimSpace(content)
+
+	if content == "" {
+		return fmt.Errorf("entry content is empty")
+	}
+
+	if len(content) > maxContentLength {
+		return fmt.Errorf("entry content is too long, the limit is %d characters", maxContentLength)
+	}
+
+	// secret values are stored as %frank(name) references, like everywhere else the model sees them
+	if err := s.queries.UpsertKnowledge(ctx, database.UpsertKnowledgeParams{
+		Name:    name,
+		Content: s.secretService.Redact(content),
+		Created: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("UpsertKnowledge: %w", err)
+	}
+
+	return nil
+}
+
+// WritableFrom reports whether prompts of the channel may change knowledge
+func (s *Service) WritableFrom(channel string) bool {
+	return slices.Contains(s.cfg.KnowledgeChannels, channel)
+}
+
+// Forget deletes an entry saved in the database
+func (s *Service) Forget(ctx context.Context, name string) error {
+	if entry, ok := s.lockedReadOnlyEntry(name); ok {
+		return fmt.Errorf("entry %s is defined in %s knowledge and can't be deleted", name, entry.Source)
+	}
+
+	deleted, err := s.queries.DeleteKnowledge(ctx, name)
+	if err != nil {
+		return fmt.Errorf("DeleteKnowledge: %w", err)
+	}
+
+	if deleted == 0 {
+		return fmt.Errorf("entry %s not found", name)
+	}
+
+	return nil
+}
+
+func (s *Service) lockedReadOnlyEntry(name string) (dto.KnowledgeEntry, bool) {
+	s.filesMu.RLock()
+	defer s.filesMu.RUnlock()
+
+	return s.readOnlyEntry(name)
+}
+
+// readOnlyEntry finds an entry which isn't stored in the database, filesMu must be held
+func (s *Service) readOnlyEntry(name string) (dto.KnowledgeEntry, bool) {
+	if entry, ok := s.knowledgeBase[name]; ok {
+		return entry, true
+	}
+
+	entry, ok := s.files[name]
+
+	return entry, ok
 }
 
-func (s *Service) generateSystemPrompt() string {
-	names := make([]string, 0)
+// Run reloads the knowledge directory whenever its files change
+func (s *Service) Run(ctx context.Context) {
+	if s.cfg.KnowledgeDir.Path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.KnowledgeDir.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := s.reloadFiles()
+		if err != nil {
+			// the previous entries stay in use until the files are fixed
+			slog.ErrorContext(ctx, "Failed to reload knowledge directory",
+				slog.String("path", s.cfg.KnowledgeDir.Path),
+				slog.Any("error", err),
+			)
+
+			continue
+		}
+
+		if reloaded {
+			s.filesMu.RLock()
+			count := len(s.files)
+			s.filesMu.RUnlock()
 
-	for name := range s.knowledgeBase {
-		names = append(names, name)
+			slog.InfoContext(ctx, "Knowledge directory reloaded",
+				slog.String("path", s.cfg.KnowledgeDir.Path),
+				slog.Int("entries", count),
+			)
+		}
+	}
+}
+
+// reloadFiles loads the knowledge directory if it has changed since the last load
+func (s *Service) reloadFiles() (bool, error) {
+	fingerprint, err := directoryFingerprint(s.cfg.KnowledgeDir.Path)
+	if err != nil {
+		return false, fmt.Errorf("directoryFingerprint: %w", err)
+	}
+
+	s.filesMu.RLock()
+	unchanged := fingerprint == s.filesFingerprint
+	s.filesMu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	files, err := loadDirectory(s.cfg.KnowledgeDir.Path)
+	if err != nil {
+		return false, fmt.Errorf("loadDirectory: %w", err)
+	}
+
+	for _, entry := range files {
+		if missing := s.missingSecrets(entry); len(missing) > 0 {
+			slog.Warn("Knowledge entry requires secrets which are not set",
+				slog.String("name", entry.Name),
+				slog.String("path", entry.Path),
+				slog.Any("secrets", missing),
+			)
+		}
 	}
 
-	result := systemPromptTemplate
+	s.filesMu.Lock()
+	defer s.filesMu.Unlock()
 
-	result = strings.ReplaceAll(result, "{names}", strings.Join(names, ", "))
+	s.files = files
+	s.filesFingerprint = fingerprint
 
-	return result
+	return true, nil
 }
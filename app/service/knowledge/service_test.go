@@ -0,0 +1,75 @@
+package knowledge
+
+import (
+	"context"
+	"frank/app/dto"
+	"frank/pkg/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Remember_Rejected(t *testing.T) {
+	service := &Service{
+		knowledgeBase: map[string]dto.KnowledgeEntry{
+			"steam_comments_api": {Name: "steam_comments_api", Source: dto.BaseKnowledgeSource},
+			"home_address":       {Name: "home_address", Source: dto.ConfigKnowledgeSource},
+		},
+		files: map[string]dto.KnowledgeEntry{
+			"recipes/weather": {Name: "recipes/weather", Source: dto.FileKnowledgeSource},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		entryName   string
+		content     string
+		errContains string
+	}{
+		{"empty name", "", "content", "invalid entry name"},
+		{"name with spaces", "my entry", "content", "invalid entry name"},
+		{"name with a slash", "recipes/weather", "content", "invalid entry name"},
+		{"too long name", strings.Repeat("a", 65), "content", "invalid entry name"},
+		{"base entry", "steam_comments_api", "content", "defined in base knowledge"},
+		{"config entry", "home_address", "content", "defined in config knowledge"},
+		{"empty content", "note", "  \n ", "content is empty"},
+		{"too long content", "note", strings.Repeat("a", maxContentLength+1), "too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Remember(context.Background(), tt.entryName, tt.content)
+			assert.ErrorContains(t, err, tt.errContains)
+		})
+	}
+}
+
+func TestService_Forget_ReadOnly(t *testing.T) {
+	service := &Service{
+		knowledgeBase: map[string]dto.KnowledgeEntry{
+			"steam_comments_api": {Name: "steam_comments_api", Source: dto.BaseKnowledgeSource},
+			"home_address":       {Name: "home_address", Source: dto.ConfigKnowledgeSource},
+		},
+		files: map[string]dto.KnowledgeEntry{
+			"recipes/weather": {Name: "recipes/weather", Source: dto.FileKnowledgeSource},
+		},
+	}
+
+	assert.ErrorContains(t, service.Forget(context.Background(), "steam_comments_api"), "defined in base knowledge")
+	assert.ErrorContains(t, service.Forget(context.Background(), "home_address"), "defined in config knowledge")
+	assert.ErrorContains(t, service.Forget(context.Background(), "recipes/weather"), "defined in file knowledge")
+}
+
+func TestService_WritableFrom(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.KnowledgeChannels = []string{dto.TelegramChannel, dto.ConsoleChannel}
+
+	service := &Service{cfg: cfg}
+
+	assert.True(t, service.WritableFrom(dto.TelegramChannel))
+	assert.True(t, service.WritableFrom(dto.ConsoleChannel))
+	assert.False(t, service.WritableFrom(dto.WebhookChannel))
+	assert.False(t, service.WritableFrom(dto.EmailChannel))
+	assert.False(t, service.WritableFrom(dto.APIChannel))
+}
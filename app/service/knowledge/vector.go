@@ -0,0 +1,181 @@
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"frank/app/dto"
+	"frank/pkg/database"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pgvector/pgvector-go"
+)
+
+// vectorRetriever selects chunks by cosine similarity of their embeddings, stored in pgvector
+type vectorRetriever struct {
+	dbConn    *pgxpool.Pool
+	queries   *database.Queries
+	embedder  Embedder
+	chunkSize int
+	threshold float64
+	limit     int
+
+	mu sync.Mutex
+}
+
+func (r *vectorRetriever) Retrieve(ctx context.Context, query string, entries []dto.KnowledgeEntry) ([]match, error) {
+	if err := r.sync(ctx, entries); err != nil {
+		return nil, fmt.Errorf("sync: %w", err)
+	}
+
+	embeddings, err := r.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embedder.Embed: %w", err)
+	}
+
+	// the similarity to a zero vector is undefined, such query has no words to match anyway
+	if isZero(embeddings[0]) {
+		return []match{}, nil
+	}
+
+	embedding := pgvector.NewVector(embeddings[0])
+
+	rows, err := r.queries.SearchKnowledgeChunks(ctx, database.SearchKnowledgeChunksParams{
+		Embedding:  &embedding,
+		MaxResults: int32(r.limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("SearchKnowledgeChunks: %w", err)
+	}
+
+	result := make([]match, 0, len(rows))
+
+	for _, row := range rows {
+		if row.Score < r.threshold {
+			break
+		}
+
+		result = append(result, match{
+			Name:    row.Name,
+			Chunk:   int(row.Chunk),
+			Content: row.Content,
+			Score:   row.Score,
+		})
+	}
+
+	return result, nil
+}
+
+// sync embeds entries which are new or changed since the last time and removes chunks of deleted entries
+func (r *vectorRetriever) sync(ctx context.Context, entries []dto.KnowledgeEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.queries.ListKnowledgeChunkHashes(ctx)
+	if err != nil {
+		return fmt.Errorf("ListKnowledgeChunkHashes: %w", err)
+	}
+
+	storedHashes := make(map[string]string, len(stored))
+	for _, row := range stored {
+		storedHashes[row.Name] = row.Hash
+	}
+
+	for _, entry := range entries {
+		hash := r.hash(entry)
+
+		if storedHashes[entry.Name] != hash {
+			if err = r.index(ctx, entry, hash); err != nil {
+				return fmt.Errorf("index %s: %w", entry.Name, err)
+			}
+		}
+
+		delete(storedHashes, entry.Name)
+	}
+
+	for name := range storedHashes {
+		if err = r.queries.DeleteKnowledgeChunks(ctx, name); err != nil {
+			return fmt.Errorf("DeleteKnowledgeChunks: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *vectorRetriever) index(ctx context.Context, entry dto.KnowledgeEntry, hash string) error {
+	chunks := chunkText(entry.Content, r.chunkSize)
+	if len(chunks) == 0 {
+		// empty entries are stored too, otherwise they would be indexed again on every prompt
+		chunks = []string{""}
+	}
+
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		texts = append(texts, indexedText(entry, chunk))
+	}
+
+	embeddings, err := r.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embedder.Embed: %w", err)
+	}
+
+	tx, err := r.dbConn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("dbConn.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	queries := r.queries.WithTx(tx)
+
+	if err = queries.DeleteKnowledgeChunks(ctx, entry.Name); err != nil {
+		return fmt.Errorf("DeleteKnowledgeChunks: %w", err)
+	}
+
+	for i, chunk := range chunks {
+		embedding := pgvector.NewVector(embeddings[i])
+
+		if err = queries.CreateKnowledgeChunk(ctx, database.CreateKnowledgeChunkParams{
+			Name:      entry.Name,
+			Chunk:     int32(i),
+			Content:   chunk,
+			Hash:      hash,
+			Embedding: &embedding,
+		}); err != nil {
+			return fmt.Errorf("CreateKnowledgeChunk: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("tx.Commit: %w", err)
+	}
+
+	slog.Info("Knowledge entry indexed",
+		slog.String("name", entry.Name),
+		slog.Int("chunks", len(chunks)),
+	)
+
+	return nil
+}
+
+// hash changes along with the content, the tags, the embedder and the chunking, any of them requires to re-embed the entry
+func (r *vectorRetriever) hash(entry dto.KnowledgeEntry) string {
+	sum := sha256.Sum256([]byte(r.embedder.ID() + "\x00" + strconv.Itoa(r.chunkSize) + "\x00" +
+		strings.Join(entry.Tags, ",") + "\x00" + entry.Content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func isZero(vector []float32) bool {
+	for _, value := range vector {
+		if value != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frank/app/client/bothub"
+	"frank/app/dto"
+	"frank/app/service/secret"
+	"frank/pkg/config"
+	"frank/pkg/database"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	_ "embed"
+
+	"github.com/samber/do"
+)
+
+//go:embed EXTRACT_PROMPT
+var extractPromptTemplate string
+
+var extractTimeout = 2 * time.Minute
+
+// memories at least this similar to an existing one are considered duplicates
+var duplicateSimilarity = 0.8
+
+// Service learns facts about the user from finished prompts, without being asked to remember them
+type Service struct {
+	appCtx        context.Context
+	cfg           *config.Config
+	queries       *database.Queries
+	bothubClient  *bothub.Client
+	secretService *secret.Service
+}
+
+func New(di *do.Injector) (*Service, error) {
+	return &Service{
+		appCtx:        do.MustInvoke[context.Context](di),
+		cfg:           do.MustInvoke[*config.Config](di),
+		queries:       do.MustInvoke[*database.Queries](di),
+		bothubClient:  do.MustInvoke[*bothub.Client](di),
+		secretService: do.MustInvoke[*secret.Service](di),
+	}, nil
+}
+
+type extractResult struct {
+	Memories []struct {
+		Content    string  `json:"content"`
+		Confidence float64 `json:"confidence"`
+		Replaces   int64   `json:"replaces,omitempty"`
+	} `json:"memories"`
+}
+
+func (s *Service) PromptStepStarted(dto.Prompt, int) {}
+
+// PromptFinished starts the extraction in the background, so that the prompt which finished isn't held up by it
+func (s *Service) PromptFinished(prompt dto.Prompt, cancelled bool) {
+	if cancelled || !slices.Contains(s.cfg.Memory.Channels, prompt.Channel) {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(s.appCtx, extractTimeout)
+		defer cancel()
+
+		if err := s.extract(ctx, prompt); err != nil {
+			slog.ErrorContext(ctx, "Failed to extract memories",
+				slog.String("prompt_id", prompt.ID.String()),
+				slog.Any("error", err),
+			)
+		}
+	}()
+}
+
+func (s *Service) extract(ctx context.Context, prompt dto.Prompt) error {
+	existing, err := s.queries.ListMemories(ctx)
+	if err != nil {
+		return fmt.Errorf("ListMemories: %w", err)
+	}
+
+	output, err := s.bothubClient.Process(ctx, bothub.Prompt{
+		SystemText: s.generateExtractPrompt(existing),
+		UserText:   s.secretService.Redact(prompt.Text),
+		Model:      bothub.ModelDeepseekChatV3,
+	})
+	if err != nil {
+		return fmt.Errorf("bothubClient.Process: %w", err)
+	}
+
+	output = strings.TrimSpace(output)
+	output = strings.TrimPrefix(output, "```json")
+	output = strings.Trim(output, "`")
+
+	var result extractResult
+
+	if err = json.Unmarshal([]byte(output), &result); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	for _, candidate := range result.Memories {
+		content := s.secretService.Redact(strings.TrimSpace(candidate.Content))
+
+		if content == "" || candidate.Confidence < *s.cfg.Memory.MinConfidence {
+			continue
+		}
+
+		if candidate.Replaces != 0 && slices.ContainsFunc(existing, func(m database.Memory) bool { return m.ID == candidate.Replaces }) {
+			if err = s.Update(ctx, candidate.Replaces, content); err != nil {
+				return fmt.Errorf("Update: %w", err)
+			}
+
+			slog.InfoContext(ctx, "Memory updated",
+				slog.Int64("id", candidate.Replaces),
+				slog.String("prompt_id", prompt.ID.String()),
+				slog.String("content", content),
+			)
+
+			continue
+		}
+
+		if isDuplicate(content, existing) {
+			continue
+		}
+
+		id, err := s.queries.CreateMemory(ctx, database.CreateMemoryParams{
+			Content:        content,
+			SourcePromptID: prompt.ID,
+			Confidence:     float32(candidate.Confidence),
+			Created:        time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("CreateMemory: %w", err)
+		}
+
+		slog.InfoContext(ctx, "Memory extracted",
+			slog.Int64("id", id),
+			slog.String("prompt_id", prompt.ID.String()),
+			slog.String("content", content),
+			slog.Float64("confidence", candidate.Confidence),
+		)
+
+		existing = append(existing, database.Memory{ID: id, Content: content})
+	}
+
+	return nil
+}
+
+func (s *Service) generateExtractPrompt(existing []database.Memory) string {
+	var builder strings.Builder
+
+	for _, memory := range existing {
+		builder.WriteString(fmt.Sprintf("- #%d: %s\n", memory.ID, memory.Content))
+	}
+
+	if len(existing) == 0 {
+		builder.WriteString("~none~\n")
+	}
+
+	return strings.ReplaceAll(extractPromptTemplate, "{memories}", strings.TrimSpace(builder.String()))
+}
+
+// Relevant returns the memories to include in the context of the prompt. All of them fit while there are few,
+// then the ones sharing most words with the prompt are preferred.
+func (s *Service) Relevant(ctx context.Context, prompt dto.Prompt) ([]string, error) {
+	if *s.cfg.Memory.Limit == 0 {
+		return []string{}, nil
+	}
+
+	memories, err := s.queries.ListMemories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ListMemories: %w", err)
+	}
+
+	if len(memories) > *s.cfg.Memory.Limit {
+		promptWords := wordSet(prompt.Text)
+
+		// the sort is stable, so recent memories go first among equally relevant ones
+		slices.Reverse(memories)
+		slices.SortStableFunc(memories, func(a, b database.Memory) int {
+			return overlap(promptWords, wordSet(b.Content)) - overlap(promptWords, wordSet(a.Content))
+		})
+
+		memories = memories[:*s.cfg.Memory.Limit]
+	}
+
+	result := make([]string, 0, len(memories))
+	for _, memory := range memories {
+		result = append(result, memory.Content)
+	}
+
+	return result, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]database.Memory, error) {
+	memories, err := s.queries.ListMemories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ListMemories: %w", err)
+	}
+
+	return memories, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int64, content string) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Errorf("memory content is empty")
+	}
+
+	updated, err := s.queries.UpdateMemory(ctx, database.UpdateMemoryParams{
+		ID:      id,
+		Content: s.secretService.Redact(content),
+		Updated: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("UpdateMemory: %w", err)
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("memory %d not found", id)
+	}
+
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	deleted, err := s.queries.DeleteMemory(ctx, id)
+	if err != nil {
+		return fmt.Errorf("DeleteMemory: %w", err)
+	}
+
+	if deleted == 0 {
+		return fmt.Errorf("memory %d not found", id)
+	}
+
+	return nil
+}
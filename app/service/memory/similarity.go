@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"frank/pkg/database"
+	"strings"
+	"unicode"
+)
+
+func wordSet(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	result := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		result[word] = struct{}{}
+	}
+
+	return result
+}
+
+func overlap(a, b map[string]struct{}) int {
+	var count int
+
+	for word := range a {
+		if _, ok := b[word]; ok {
+			count++
+		}
+	}
+
+	return count
+}
+
+// similarity is the Jaccard index of the word sets, rephrasings with the same words count as duplicates
+func similarity(a, b string) float64 {
+	aWords, bWords := wordSet(a), wordSet(b)
+
+	union := len(aWords) + len(bWords) - overlap(aWords, bWords)
+	if union == 0 {
+		return 1
+	}
+
+	return float64(overlap(aWords, bWords)) / float64(union)
+}
+
+func isDuplicate(content string, existing []database.Memory) bool {
+	for _, memory := range existing {
+		if similarity(content, memory.Content) >= duplicateSimilarity {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"frank/pkg/database"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDuplicate(t *testing.T) {
+	existing := []database.Memory{
+		{ID: 1, Content: "User's timezone is Europe/Moscow"},
+		{ID: 2, Content: "Anna is the user's sister"},
+	}
+
+	assert.True(t, isDuplicate("user's timezone is Europe/Moscow.", existing))
+	assert.True(t, isDuplicate("The user's sister is Anna", existing))
+	assert.False(t, isDuplicate("User's timezone is Asia/Tokyo", existing))
+	assert.False(t, isDuplicate("User prefers Ozon for shopping", existing))
+}
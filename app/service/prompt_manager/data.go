@@ -1,9 +1,19 @@
 package prompt_manager
 
-import "context"
+import (
+	"context"
+	"frank/app/dto"
+)
 
 type promptHandle struct {
-	counter   int
-	messageID int
-	cancel    context.CancelFunc
+	counter int
+	steps   int
+	prompt  dto.Prompt
+	cancel  context.CancelFunc
+}
+
+// Listener is notified about the lifecycle of prompts, e.g. to display progress in a channel
+type Listener interface {
+	PromptStepStarted(prompt dto.Prompt, step int)
+	PromptFinished(prompt dto.Prompt, cancelled bool)
 }
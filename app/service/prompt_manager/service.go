@@ -3,8 +3,8 @@ package prompt_manager
 import (
 	"context"
 	"frank/app/dto"
-	"frank/app/service/telegram_reply"
 	"frank/pkg/config"
+	"slices"
 	"sync"
 
 	"github.com/google/uuid"
@@ -12,77 +12,126 @@ import (
 )
 
 type Service struct {
-	appCtx       context.Context
-	cfg          *config.Config
-	replyService *telegram_reply.Service
+	appCtx context.Context
+	cfg    *config.Config
 
+	listeners []Listener
 	handleMap map[uuid.UUID]*promptHandle
 	mu        sync.Mutex
+	wg        sync.WaitGroup
 }
 
 func New(di *do.Injector) (*Service, error) {
 	return &Service{
-		appCtx:       do.MustInvoke[context.Context](di),
-		cfg:          do.MustInvoke[*config.Config](di),
-		replyService: do.MustInvoke[*telegram_reply.Service](di),
-		handleMap:    make(map[uuid.UUID]*promptHandle),
+		appCtx:    do.MustInvoke[context.Context](di),
+		cfg:       do.MustInvoke[*config.Config](di),
+		handleMap: make(map[uuid.UUID]*promptHandle),
 	}, nil
 }
 
-func (s *Service) CreatePrompt(messageID int, text string) dto.Prompt {
-	ctx, cancel := context.WithCancel(s.appCtx)
+func (s *Service) AddListener(listener Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *Service) CreatePrompt(channel string, messageID int, text string) dto.Prompt {
 	prompt := dto.Prompt{
 		ID:          uuid.New(),
+		Channel:     channel,
 		MessageID:   messageID,
 		Text:        text,
 		Depth:       0,
 		TextHistory: nil,
 		Attachments: nil,
-		Ctx:         ctx,
-		Cancel:      cancel,
 	}
 
+	prompt.Ctx, prompt.Cancel = context.WithCancel(dto.ContextWithPrompt(s.appCtx, prompt))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.handleMap[prompt.ID] = &promptHandle{
-		counter:   0,
-		messageID: messageID,
-		cancel:    prompt.Cancel,
+		counter: 0,
+		steps:   0,
+		prompt:  prompt,
+		cancel:  prompt.Cancel,
 	}
+	s.wg.Add(1)
 
 	return prompt
 }
 
-func (s *Service) IncPromptCounter(id uuid.UUID) {
+// IncPromptCounter registers a new step of the prompt. Listeners are called after unlocking,
+// so that a slow one doesn't block other prompts.
+func (s *Service) IncPromptCounter(prompt dto.Prompt) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
-	handle, ok := s.handleMap[id]
+	handle, ok := s.handleMap[prompt.ID]
 	if !ok {
+		s.mu.Unlock()
 		return
 	}
 
-	if handle.counter == 0 {
-		s.replyService.SetReaction(s.appCtx, handle.messageID, "👀")
-	}
 	handle.counter++
+	handle.steps++
+
+	steps := handle.steps
+	listeners := slices.Clone(s.listeners)
+
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.PromptStepStarted(prompt, steps)
+	}
 }
 
 func (s *Service) DecPromptCounter(id uuid.UUID) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
 	handle, ok := s.handleMap[id]
 	if !ok {
+		s.mu.Unlock()
 		return
 	}
 
 	handle.counter--
-	if handle.counter == 0 {
-		handle.cancel()
-		delete(s.handleMap, id)
+	if handle.counter != 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	cancelled := handle.prompt.Ctx.Err() != nil
+
+	handle.cancel()
+	delete(s.handleMap, id)
+
+	listeners := slices.Clone(s.listeners)
+
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener.PromptFinished(handle.prompt, cancelled)
+	}
+
+	// Wait returns only after the listeners have been notified, e.g. the final reply is delivered
+	s.wg.Done()
+}
 
-		s.replyService.SetReaction(s.appCtx, handle.messageID, "👍")
+// Cancel cancels all branches of a single prompt. Returns false if the prompt is unknown or already finished.
+func (s *Service) Cancel(id uuid.UUID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handle, ok := s.handleMap[id]
+	if !ok {
+		return false
 	}
+
+	handle.cancel()
+
+	return true
 }
 
 func (s *Service) CancelAll() {
@@ -93,3 +142,8 @@ func (s *Service) CancelAll() {
 		handle.cancel()
 	}
 }
+
+// Wait blocks until every created prompt has finished
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
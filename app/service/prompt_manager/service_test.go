@@ -0,0 +1,68 @@
+package prompt_manager
+
+import (
+	"context"
+	"frank/app/dto"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingListener blocks in PromptFinished until it is released, like a slow network call
+type blockingListener struct {
+	finished chan uuid.UUID
+	release  chan struct{}
+}
+
+func (l *blockingListener) PromptStepStarted(dto.Prompt, int) {}
+
+func (l *blockingListener) PromptFinished(prompt dto.Prompt, _ bool) {
+	l.finished <- prompt.ID
+	<-l.release
+}
+
+func TestService_SlowListenerDoesNotBlockOtherPrompts(t *testing.T) {
+	service := &Service{
+		appCtx:    context.Background(),
+		handleMap: make(map[uuid.UUID]*promptHandle),
+	}
+
+	listener := &blockingListener{
+		finished: make(chan uuid.UUID, 1),
+		release:  make(chan struct{}),
+	}
+	service.AddListener(listener)
+
+	first := service.CreatePrompt(dto.ConsoleChannel, 1, "first")
+	service.IncPromptCounter(first)
+
+	go service.DecPromptCounter(first.ID)
+
+	select {
+	case id := <-listener.finished:
+		assert.Equal(t, first.ID, id)
+	case <-time.After(time.Second):
+		require.FailNow(t, "PromptFinished wasn't called")
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		second := service.CreatePrompt(dto.ConsoleChannel, 2, "second")
+		service.IncPromptCounter(second)
+		service.Cancel(second.ID)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "prompt manager is blocked by the listener")
+	}
+
+	close(listener.release)
+}
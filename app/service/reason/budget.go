@@ -0,0 +1,79 @@
+package reason
+
+import (
+	"fmt"
+	"frank/app/dto"
+	"frank/pkg/budget"
+)
+
+// room for the notes about truncated and dropped parts
+var reportReserveTokens = 200
+
+// promptParts are the optional parts of the prompts which fit the context window
+type promptParts struct {
+	Attachments []dto.Attachment
+	Knowledge   []string
+	Memories    []string
+	History     []string
+	Report      budget.Report
+}
+
+func (s *Service) contextLimit(model string) int {
+	if limit, ok := s.cfg.ContextWindow.Limits[model]; ok {
+		return limit
+	}
+
+	return s.cfg.ContextWindow.DefaultLimit
+}
+
+// fitContext selects the parts in the order of importance: attachments are the data the step works on,
+// knowledge and memories tell how to do it, and older history steps matter the least.
+// Attachments and history are ordered newest first.
+func (s *Service) fitContext(model, required string, prompt *dto.Prompt, knowledge, memories []string) promptParts {
+	b := budget.New(s.contextLimit(model) - s.cfg.ContextWindow.OutputReserve)
+	b.Reserve(budget.EstimateTokens(required) + reportReserveTokens)
+
+	var parts promptParts
+
+	for _, att := range prompt.Attachments {
+		// a single huge attachment must leave room for the rest
+		if content, ok := b.AddTruncated("attachment "+att.Name, att.Content, 0.5); ok {
+			parts.Attachments = append(parts.Attachments, dto.Attachment{Name: att.Name, Content: content})
+		}
+	}
+
+	for i, entry := range knowledge {
+		if b.Add(fmt.Sprintf("knowledge entry %d", i+1), entry) {
+			parts.Knowledge = append(parts.Knowledge, entry)
+		}
+	}
+
+	for i, memory := range memories {
+		if b.Add(fmt.Sprintf("memory %d", i+1), memory) {
+			parts.Memories = append(parts.Memories, memory)
+		}
+	}
+
+	// a gap in the history would be confusing, so everything older than the first dropped step is dropped too
+	dropping := false
+
+	for i, text := range prompt.TextHistory {
+		name := fmt.Sprintf("history step %d", len(prompt.TextHistory)-i)
+
+		if dropping {
+			b.Drop(name)
+			continue
+		}
+
+		if !b.Add(name, text) {
+			dropping = true
+			continue
+		}
+
+		parts.History = append(parts.History, text)
+	}
+
+	parts.Report = b.Report()
+
+	return parts
+}
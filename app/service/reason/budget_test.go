@@ -0,0 +1,49 @@
+package reason
+
+import (
+	"frank/app/dto"
+	"frank/pkg/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_FitContext(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.ContextWindow.Limits = map[string]int{"small": 5000}
+	cfg.ContextWindow.DefaultLimit = 100000
+	cfg.ContextWindow.OutputReserve = 1000
+
+	service := &Service{cfg: cfg}
+
+	prompt := &dto.Prompt{
+		Text: "summarize",
+		Attachments: []dto.Attachment{
+			{Name: "page", Content: strings.Repeat("word ", 4000)},
+		},
+		TextHistory: []string{
+			strings.Repeat("new ", 100),
+			strings.Repeat("old ", 2000),
+			"oldest",
+		},
+	}
+
+	parts := service.fitContext("small", strings.Repeat("x", 2000), prompt, []string{"recipe", strings.Repeat("y", 8000)}, []string{"fact"})
+
+	require.Len(t, parts.Attachments, 1)
+	assert.Contains(t, parts.Attachments[0].Content, "...[truncated, about")
+	assert.Equal(t, []string{"recipe"}, parts.Knowledge)
+	assert.Equal(t, []string{"fact"}, parts.Memories)
+	assert.Equal(t, []string{strings.Repeat("new ", 100)}, parts.History)
+
+	assert.Equal(t, []string{"attachment page"}, parts.Report.Truncated)
+	assert.Equal(t, []string{"knowledge entry 2", "history step 2", "history step 1"}, parts.Report.Dropped)
+	assert.LessOrEqual(t, parts.Report.Used, 4000)
+
+	parts = service.fitContext("large", "", prompt, nil, nil)
+	assert.Empty(t, parts.Report.Truncated)
+	assert.Empty(t, parts.Report.Dropped)
+	assert.Len(t, parts.History, 3)
+}
@@ -6,8 +6,10 @@ import (
 	"frank/app/client/bothub"
 	"frank/app/dto"
 	"frank/app/service/knowledge"
+	"frank/app/service/memory"
 	"frank/app/service/prompt_manager"
-	"frank/app/service/telegram_reply"
+	"frank/app/service/reply"
+	"frank/app/service/secret"
 	"frank/pkg/config"
 	"frank/pkg/database"
 	"log/slog"
@@ -16,7 +18,6 @@ import (
 
 	_ "embed"
 
-	"github.com/elliotchance/pie/v2"
 	"github.com/samber/do"
 )
 
@@ -36,10 +37,12 @@ type Service struct {
 	appCtx           context.Context
 	cfg              *config.Config
 	queries          *database.Queries
-	replierService   *telegram_reply.Service
+	replierService   *reply.Service
 	bothubClient     *bothub.Client
 	knowledgeService *knowledge.Service
+	memoryService    *memory.Service
 	promptManager    *prompt_manager.Service
+	secretService    *secret.Service
 
 	actor Actor
 }
@@ -49,10 +52,12 @@ func New(di *do.Injector) (*Service, error) {
 		appCtx:           do.MustInvoke[context.Context](di),
 		cfg:              do.MustInvoke[*config.Config](di),
 		queries:          do.MustInvoke[*database.Queries](di),
-		replierService:   do.MustInvoke[*telegram_reply.Service](di),
+		replierService:   do.MustInvoke[*reply.Service](di),
 		knowledgeService: do.MustInvoke[*knowledge.Service](di),
+		memoryService:    do.MustInvoke[*memory.Service](di),
 		bothubClient:     do.MustInvoke[*bothub.Client](di),
 		promptManager:    do.MustInvoke[*prompt_manager.Service](di),
+		secretService:    do.MustInvoke[*secret.Service](di),
 	}, nil
 }
 
@@ -61,17 +66,19 @@ func (s *Service) SetActor(actor Actor) {
 }
 
 func (s *Service) Handle(prompt dto.Prompt) {
+	replyCtx := dto.ContextWithPrompt(s.appCtx, prompt)
+
 	if prompt.Depth > maxPromptDepth {
-		slog.Error("Max prompt depth reached",
+		slog.ErrorContext(replyCtx, "Max prompt depth reached",
 			slog.String("text", prompt.Text),
 		)
 
-		s.replierService.Reply(s.appCtx, "Failed to handle prompt: max prompt depth reached")
+		s.replierService.Reply(replyCtx, "Failed to handle prompt: max prompt depth reached")
 
 		return
 	}
 
-	s.promptManager.IncPromptCounter(prompt.ID)
+	s.promptManager.IncPromptCounter(prompt)
 
 	go func() {
 		ctx, cancel := context.WithTimeout(prompt.Ctx, reasonTimeout)
@@ -90,7 +97,7 @@ func (s *Service) Handle(prompt dto.Prompt) {
 				slog.Any("error", err),
 			)
 
-			s.replierService.Reply(s.appCtx, "Failed to handle prompt: "+err.Error())
+			s.replierService.Reply(replyCtx, "Failed to handle prompt: "+err.Error())
 		} else {
 			slog.Info("Prompt handle success",
 				slog.String("text", prompt.Text),
@@ -100,16 +107,17 @@ func (s *Service) Handle(prompt dto.Prompt) {
 }
 
 func (s *Service) handlePromptImpl(ctx context.Context, prompt dto.Prompt) error {
-	systemPrompt, err := s.generateSystemPrompt(ctx, &prompt)
+	model := bothub.ModelDeepseekChatV3
+
+	systemPrompt, userPrompt, err := s.generatePrompts(ctx, &prompt, model)
 	if err != nil {
-		return fmt.Errorf("failed to generate system prompt: %w", err)
+		return fmt.Errorf("failed to generate prompts: %w", err)
 	}
 
-	userPrompt := prompt.Text + "\n\n" + s.generateAttachmentsDescription(&prompt)
-
 	reasonOutput, err := s.bothubClient.Process(ctx, bothub.Prompt{
-		SystemText: systemPrompt,
-		UserText:   userPrompt,
+		SystemText: s.secretService.Redact(systemPrompt),
+		UserText:   s.secretService.Redact(userPrompt),
+		Model:      model,
 	})
 	if err != nil {
 		return fmt.Errorf("gptClient.Process: %w", err)
@@ -131,28 +139,50 @@ func (s *Service) handlePromptImpl(ctx context.Context, prompt dto.Prompt) error
 	return nil
 }
 
-func (s *Service) generateSystemPrompt(ctx context.Context, prompt *dto.Prompt) (string, error) {
-	contextDescription, err := s.generateContextDescription(ctx, prompt)
+// generatePrompts returns the system and the user prompts, fitted into the context window of the model
+func (s *Service) generatePrompts(ctx context.Context, prompt *dto.Prompt, model string) (string, string, error) {
+	contextEntries, err := s.knowledgeService.GetRelevant(ctx, *prompt)
+	if err != nil {
+		return "", "", fmt.Errorf("knowledgeService.GetRelevant: %w", err)
+	}
+
+	memories, err := s.memoryService.Relevant(ctx, *prompt)
 	if err != nil {
-		return "", fmt.Errorf("generateContextDescription: %w", err)
+		return "", "", fmt.Errorf("memoryService.Relevant: %w", err)
 	}
 
-	result := systemPromptTemplate
+	template := systemPromptTemplate
 
-	result = strings.ReplaceAll(result, "{root_commands}", s.actor.RootCommandsDescription())
-	result = strings.ReplaceAll(result, "{additional_commands}", s.actor.AdditionalCommandsDescription())
-	result = strings.ReplaceAll(result, "{context}", contextDescription)
-	result = strings.ReplaceAll(result, "{history}", s.generateHistoryDescription(prompt))
+	template = strings.ReplaceAll(template, "{root_commands}", s.actor.RootCommandsDescription())
+	template = strings.ReplaceAll(template, "{additional_commands}", s.actor.AdditionalCommandsDescription())
 
-	return result, nil
-}
+	baseContext := s.generateBaseContextDescription()
 
-func (s *Service) generateContextDescription(ctx context.Context, prompt *dto.Prompt) (string, error) {
-	contextEntries, err := s.knowledgeService.GetRelevant(ctx, *prompt)
-	if err != nil {
-		return "", fmt.Errorf("knowledgeService.GetRelevant: %w", err)
+	parts := s.fitContext(model, template+baseContext+prompt.Text, prompt, contextEntries, memories)
+
+	if len(parts.Report.Truncated) > 0 || len(parts.Report.Dropped) > 0 {
+		slog.WarnContext(ctx, "Prompt doesn't fit the context window",
+			slog.String("prompt_id", prompt.ID.String()),
+			slog.String("model", model),
+			slog.Int("limit", parts.Report.Limit),
+			slog.Int("used", parts.Report.Used),
+			slog.Any("truncated", parts.Report.Truncated),
+			slog.Any("dropped", parts.Report.Dropped),
+		)
 	}
 
+	systemPrompt := template
+
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{context}", baseContext+s.generateContextDescription(parts))
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{history}", s.generateHistoryDescription(parts.History))
+
+	userPrompt := prompt.Text + "\n\n" + s.generateAttachmentsDescription(parts.Attachments)
+
+	return systemPrompt, userPrompt, nil
+}
+
+// generateBaseContextDescription describes the context which is always included
+func (s *Service) generateBaseContextDescription() string {
 	var builder strings.Builder
 
 	builder.WriteString("- Current time: ")
@@ -160,26 +190,54 @@ func (s *Service) generateContextDescription(ctx context.Context, prompt *dto.Pr
 	builder.WriteString("\n")
 
 	builder.WriteString("- Available secrets: ")
-	builder.WriteString(strings.Join(pie.Keys(s.cfg.Secrets), ", "))
+	builder.WriteString(strings.Join(s.secretDescriptions(), ", "))
 	builder.WriteString("\n")
 
-	for _, entry := range contextEntries {
+	return builder.String()
+}
+
+func (s *Service) generateContextDescription(parts promptParts) string {
+	var builder strings.Builder
+
+	if len(parts.Memories) > 0 {
+		builder.WriteString("- Known facts about the user:\n")
+
+		for _, memory := range parts.Memories {
+			builder.WriteString("  - ")
+			builder.WriteString(memory)
+			builder.WriteString("\n")
+		}
+	}
+
+	for _, entry := range parts.Knowledge {
 		builder.WriteString("- ")
 		builder.WriteString(entry)
 		builder.WriteString("\n")
 	}
 
-	return builder.String(), nil
+	if len(parts.Report.Truncated) > 0 {
+		builder.WriteString("- Truncated to fit the context window: ")
+		builder.WriteString(strings.Join(parts.Report.Truncated, ", "))
+		builder.WriteString("\n")
+	}
+
+	if len(parts.Report.Dropped) > 0 {
+		builder.WriteString("- Left out to fit the context window: ")
+		builder.WriteString(strings.Join(parts.Report.Dropped, ", "))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
 }
 
-func (s *Service) generateHistoryDescription(prompt *dto.Prompt) string {
-	if len(prompt.TextHistory) == 0 {
+func (s *Service) generateHistoryDescription(history []string) string {
+	if len(history) == 0 {
 		return "~this is a first prompt for this request~"
 	}
 
 	var builder strings.Builder
 
-	for _, text := range prompt.TextHistory {
+	for _, text := range history {
 		builder.WriteString("- ")
 		builder.WriteString(text)
 		builder.WriteString("\n")
@@ -190,8 +248,8 @@ func (s *Service) generateHistoryDescription(prompt *dto.Prompt) string {
 	return builder.String()
 }
 
-func (s *Service) generateAttachmentsDescription(prompt *dto.Prompt) string {
-	if len(prompt.Attachments) == 0 {
+func (s *Service) generateAttachmentsDescription(attachments []dto.Attachment) string {
+	if len(attachments) == 0 {
 		return ""
 	}
 
@@ -199,7 +257,7 @@ func (s *Service) generateAttachmentsDescription(prompt *dto.Prompt) string {
 
 	builder.WriteString("# ATTACHMENTS\n\n")
 
-	for _, att := range prompt.Attachments {
+	for _, att := range attachments {
 		builder.WriteString("## ")
 		builder.WriteString(att.Name)
 		builder.WriteString("\n")
@@ -209,3 +267,19 @@ func (s *Service) generateAttachmentsDescription(prompt *dto.Prompt) string {
 
 	return builder.String()
 }
+
+// secretDescriptions lists secret names along with their scopes, so that the model doesn't try forbidden requests
+func (s *Service) secretDescriptions() []string {
+	names := s.secretService.Names()
+	descriptions := make([]string, 0, len(names))
+
+	for _, name := range names {
+		if scope := s.secretService.ScopeDescription(name); scope != "" {
+			name += " (" + scope + ")"
+		}
+
+		descriptions = append(descriptions, name)
+	}
+
+	return descriptions
+}
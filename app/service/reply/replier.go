@@ -0,0 +1,8 @@
+package reply
+
+import "context"
+
+// Replier delivers the output of a prompt back to the user through a single channel
+type Replier interface {
+	Reply(ctx context.Context, text string)
+}
@@ -0,0 +1,66 @@
+package reply
+
+import (
+	"context"
+	"frank/app/service/secret"
+	"frank/pkg/util"
+	"log/slog"
+	"sync"
+
+	"github.com/samber/do"
+)
+
+// Service routes replies to the channel of the prompt stored in the context. Secret values are redacted from replies.
+type Service struct {
+	secretService  *secret.Service
+	channels       map[string]Replier
+	defaultChannel string
+	mu             sync.RWMutex
+}
+
+func New(di *do.Injector) (*Service, error) {
+	return &Service{
+		secretService: do.MustInvoke[*secret.Service](di),
+		channels:      make(map[string]Replier),
+	}, nil
+}
+
+// Register adds a channel. The first registered channel receives replies that can't be routed otherwise.
+func (s *Service) Register(channel string, replier Replier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.defaultChannel == "" {
+		s.defaultChannel = channel
+	}
+
+	s.channels[channel] = replier
+}
+
+func (s *Service) Reply(ctx context.Context, text string) {
+	channel, _ := ctx.Value(util.ChannelContextKey).(string)
+
+	replier, ok := s.getReplier(channel)
+	if !ok {
+		slog.ErrorContext(ctx, "No channel to deliver reply",
+			slog.String("channel", channel),
+			slog.String("text", text),
+		)
+		return
+	}
+
+	replier.Reply(ctx, s.secretService.Redact(text))
+}
+
+func (s *Service) getReplier(channel string) (Replier, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if replier, ok := s.channels[channel]; ok {
+		return replier, true
+	}
+
+	replier, ok := s.channels[s.defaultChannel]
+
+	return replier, ok
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"frank/app/dto"
+	"net/url"
+)
+
+// FakeProvider returns canned results without network access, for tests and local runs
+type FakeProvider struct {
+	results map[string][]dto.WebSearchResult
+}
+
+// NewFakeProvider answers known queries with the given results and any other query with generated ones
+func NewFakeProvider(results map[string][]dto.WebSearchResult) *FakeProvider {
+	return &FakeProvider{results: results}
+}
+
+func (p *FakeProvider) WebSearch(_ context.Context, query string, opts dto.WebSearchOptions) ([]dto.WebSearchResult, error) {
+	if results, ok := p.results[query]; ok {
+		return results, nil
+	}
+
+	count := opts.Count
+	if count <= 0 {
+		count = defaultResultCount
+	}
+
+	page := max(opts.Page, 1)
+	results := make([]dto.WebSearchResult, 0, count)
+
+	for i := range count {
+		number := (page-1)*count + i + 1
+
+		results = append(results, dto.WebSearchResult{
+			Title:   fmt.Sprintf("Result %d for %s", number, query),
+			URL:     fmt.Sprintf("https://example.com/search/%d?q=%s", number, url.QueryEscape(query)),
+			Domain:  "example.com",
+			Snippet: fmt.Sprintf("Fake snippet %d", number),
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,175 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"frank/app/client/brave"
+	"frank/app/client/searxng"
+	"frank/app/client/yandex"
+	"frank/app/dto"
+	"frank/pkg/config"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/samber/do"
+)
+
+var defaultResultCount = 10
+
+type Provider interface {
+	WebSearch(ctx context.Context, query string, opts dto.WebSearchOptions) ([]dto.WebSearchResult, error)
+}
+
+type namedProvider struct {
+	name     string
+	provider Provider
+}
+
+// Service queries all configured search providers and merges their results
+type Service struct {
+	providers []namedProvider
+}
+
+func New(di *do.Injector) (*Service, error) {
+	cfg := do.MustInvoke[*config.Config](di)
+
+	service := &Service{}
+
+	for _, name := range cfg.Search.Providers {
+		var provider Provider
+
+		switch name {
+		case "yandex":
+			provider = do.MustInvoke[*yandex.Client](di)
+		case "searxng":
+			provider = do.MustInvoke[*searxng.Client](di)
+		case "brave":
+			provider = do.MustInvoke[*brave.Client](di)
+		case "fake":
+			provider = NewFakeProvider(nil)
+		default:
+			return nil, fmt.Errorf("unknown search provider %s", name)
+		}
+
+		service.AddProvider(name, provider)
+	}
+
+	return service, nil
+}
+
+func (s *Service) AddProvider(name string, provider Provider) {
+	s.providers = append(s.providers, namedProvider{name: name, provider: provider})
+}
+
+// WebSearch queries the providers concurrently. Results are interleaved by rank, so that every provider
+// contributes its best results first, and repeated URLs are dropped. Failing providers are skipped
+// as long as at least one succeeds.
+func (s *Service) WebSearch(ctx context.Context, query string, opts dto.WebSearchOptions) ([]dto.WebSearchResult, error) {
+	if len(s.providers) == 0 {
+		return nil, fmt.Errorf("no search providers configured")
+	}
+
+	providerResults := make([][]dto.WebSearchResult, len(s.providers))
+	providerErrors := make([]error, len(s.providers))
+
+	var wg sync.WaitGroup
+
+	for i, provider := range s.providers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results, err := provider.provider.WebSearch(ctx, query, opts)
+			if err != nil {
+				providerErrors[i] = fmt.Errorf("%s: %w", provider.name, err)
+
+				slog.WarnContext(ctx, "Search provider failed",
+					slog.String("provider", provider.name),
+					slog.Any("error", err),
+				)
+
+				return
+			}
+
+			providerResults[i] = results
+		}()
+	}
+
+	wg.Wait()
+
+	failed := 0
+	for _, err := range providerErrors {
+		if err != nil {
+			failed++
+		}
+	}
+
+	if failed == len(s.providers) {
+		return nil, errors.Join(providerErrors...)
+	}
+
+	count := opts.Count
+	if count <= 0 {
+		count = defaultResultCount
+	}
+
+	return mergeResults(providerResults, count), nil
+}
+
+func mergeResults(providerResults [][]dto.WebSearchResult, count int) []dto.WebSearchResult {
+	merged := make([]dto.WebSearchResult, 0, count)
+	seen := make(map[string]bool)
+
+	for rank := 0; len(merged) < count; rank++ {
+		exhausted := true
+
+		for _, results := range providerResults {
+			if rank >= len(results) {
+				continue
+			}
+
+			exhausted = false
+
+			key := normalizeURL(results[rank].URL)
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+
+			merged = append(merged, results[rank])
+			if len(merged) == count {
+				break
+			}
+		}
+
+		if exhausted {
+			break
+		}
+	}
+
+	return merged
+}
+
+// normalizeURL ignores differences which don't change the page: scheme, www, letter case of the host,
+// trailing slash and fragment
+func normalizeURL(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	path := strings.TrimSuffix(parsed.EscapedPath(), "/")
+
+	normalized := host + path
+	if parsed.RawQuery != "" {
+		normalized += "?" + parsed.RawQuery
+	}
+
+	return normalized
+}
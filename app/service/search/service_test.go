@@ -0,0 +1,95 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"frank/app/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingProvider struct{}
+
+func (f *failingProvider) WebSearch(context.Context, string, dto.WebSearchOptions) ([]dto.WebSearchResult, error) {
+	return nil, errors.New("quota exceeded")
+}
+
+func urls(results []dto.WebSearchResult) []string {
+	result := make([]string, 0, len(results))
+	for _, r := range results {
+		result = append(result, r.URL)
+	}
+
+	return result
+}
+
+func TestService_WebSearch(t *testing.T) {
+	first := NewFakeProvider(map[string][]dto.WebSearchResult{
+		"go": {
+			{URL: "https://go.dev/"},
+			{URL: "https://pkg.go.dev/std"},
+			{URL: "https://example.com/a"},
+		},
+	})
+	second := NewFakeProvider(map[string][]dto.WebSearchResult{
+		"go": {
+			{URL: "http://www.go.dev"},
+			{URL: "https://example.com/b"},
+			{URL: "https://example.com/a#section"},
+			{URL: "https://example.com/c"},
+		},
+	})
+
+	t.Run("results are interleaved and deduplicated", func(t *testing.T) {
+		service := &Service{}
+		service.AddProvider("first", first)
+		service.AddProvider("second", second)
+
+		results, err := service.WebSearch(context.Background(), "go", dto.WebSearchOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"https://go.dev/",
+			"https://pkg.go.dev/std",
+			"https://example.com/b",
+			"https://example.com/a",
+			"https://example.com/c",
+		}, urls(results))
+	})
+
+	t.Run("count limits merged results", func(t *testing.T) {
+		service := &Service{}
+		service.AddProvider("first", first)
+		service.AddProvider("second", second)
+
+		results, err := service.WebSearch(context.Background(), "go", dto.WebSearchOptions{Count: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"https://go.dev/", "https://pkg.go.dev/std"}, urls(results))
+	})
+
+	t.Run("failing provider is skipped", func(t *testing.T) {
+		service := &Service{}
+		service.AddProvider("failing", &failingProvider{})
+		service.AddProvider("fake", NewFakeProvider(nil))
+
+		results, err := service.WebSearch(context.Background(), "anything", dto.WebSearchOptions{Count: 3, Page: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"https://example.com/search/4?q=anything",
+			"https://example.com/search/5?q=anything",
+			"https://example.com/search/6?q=anything",
+		}, urls(results))
+	})
+
+	t.Run("all providers failing", func(t *testing.T) {
+		service := &Service{}
+		service.AddProvider("failing", &failingProvider{})
+
+		_, err := service.WebSearch(context.Background(), "go", dto.WebSearchOptions{})
+		assert.ErrorContains(t, err, "failing: quota exceeded")
+	})
+}
@@ -0,0 +1,99 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const masterKeyEnv = "FRANK_MASTER_KEY"
+
+const masterKeySize = 32
+
+// loadMasterKey reads the key from the env variable or the configured file. Returns nil if neither is set.
+func loadMasterKey(keyFile string) ([]byte, error) {
+	encoded := os.Getenv(masterKeyEnv)
+
+	if encoded == "" && keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read master key file: %w", err)
+		}
+
+		encoded = string(data)
+	}
+
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil
+	}
+
+	return decodeMasterKey(encoded)
+}
+
+func decodeMasterKey(encoded string) ([]byte, error) {
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == masterKeySize {
+		return key, nil
+	}
+
+	if key, err := base64.StdEncoding.DecodeString(encoded); err == nil && len(key) == masterKeySize {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("master key must be a base64 or hex encoded %d byte key", masterKeySize)
+}
+
+// encrypt seals the value with AES-GCM. The secret name is used as additional data,
+// so that a ciphertext can't be moved to another name. The nonce is prepended to the result.
+func encrypt(key []byte, name, value string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("rand.Read: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, []byte(value), []byte(name)), nil
+}
+
+func decrypt(key []byte, name string, data []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("ciphertext is too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("gcm.Open: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher.NewGCM: %w", err)
+	}
+
+	return gcm, nil
+}
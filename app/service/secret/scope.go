@@ -0,0 +1,99 @@
+package secret
+
+import (
+	"fmt"
+	"frank/app/dto"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/elliotchance/pie/v2"
+)
+
+var referenceRegexp = regexp.MustCompile(`%frank\(([a-zA-Z0-9_]+)\)`)
+
+// References returns the unique names of secrets referenced in the text
+func References(text string) []string {
+	var names []string
+
+	for _, match := range referenceRegexp.FindAllStringSubmatch(text, -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+
+	return names
+}
+
+// CheckScope returns an error if a secret referenced in the text may not be sent to the target URL at the given location
+func (s *Service) CheckScope(text string, target *url.URL, location dto.SecretLocation) error {
+	for _, name := range References(text) {
+		scope, ok := s.cfg.SecretScopes[name]
+		if !ok {
+			continue
+		}
+
+		if len(scope.Locations) > 0 && !slices.Contains(scope.Locations, string(location)) {
+			return fmt.Errorf("secret '%s' is not allowed in the request %s, allowed locations: %s",
+				name, location, strings.Join(scope.Locations, ", "))
+		}
+
+		if len(scope.Hosts) > 0 && !pie.Any(scope.Hosts, func(pattern string) bool { return matchHost(pattern, target) }) {
+			return fmt.Errorf("secret '%s' is not allowed to be sent to %s, allowed hosts: %s",
+				name, target.Host, strings.Join(scope.Hosts, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ScopeDescription returns a short human-readable description of where the secret may be used
+func (s *Service) ScopeDescription(name string) string {
+	scope, ok := s.cfg.SecretScopes[name]
+	if !ok {
+		return ""
+	}
+
+	var parts []string
+
+	if len(scope.Hosts) > 0 {
+		parts = append(parts, "hosts: "+strings.Join(scope.Hosts, ", "))
+	}
+
+	if len(scope.Locations) > 0 {
+		parts = append(parts, "locations: "+strings.Join(scope.Locations, ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// matchHost supports exact hosts, *.example.com subdomain wildcards and URL prefixes
+func matchHost(pattern string, target *url.URL) bool {
+	if strings.Contains(pattern, "://") {
+		targetURL := target.String()
+
+		if target.User != nil || !strings.HasPrefix(targetURL, pattern) {
+			return false
+		}
+
+		_, afterScheme, _ := strings.Cut(pattern, "://")
+		if strings.Contains(afterScheme, "/") {
+			return true
+		}
+
+		// https://api.example.com must not match https://api.example.com.attacker.com
+		rest := targetURL[len(pattern):]
+
+		return rest == "" || strings.ContainsAny(rest[:1], "/?#")
+	}
+
+	hostname := strings.ToLower(target.Hostname())
+	pattern = strings.ToLower(pattern)
+
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(hostname, "."+suffix)
+	}
+
+	return hostname == pattern
+}
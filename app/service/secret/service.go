@@ -1,23 +1,78 @@
 package secret
 
 import (
+	"context"
+	"fmt"
 	"frank/pkg/config"
 	"frank/pkg/database"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/samber/do"
 )
 
+var nameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]{1,64}$`)
+
+// shorter values are not redacted, otherwise ordinary words and numbers would be mangled
+var minRedactLength = 4
+
+// Service resolves %frank(name) references from config secrets and from runtime secrets,
+// which are stored encrypted in the database and kept decrypted in memory
 type Service struct {
-	cfg     *config.Config
-	queries *database.Queries
+	cfg       *config.Config
+	queries   *database.Queries
+	masterKey []byte
+
+	runtime map[string]string
+	mu      sync.RWMutex
 }
 
 func New(di *do.Injector) (*Service, error) {
-	return &Service{
-		cfg:     do.MustInvoke[*config.Config](di),
-		queries: do.MustInvoke[*database.Queries](di),
-	}, nil
+	cfg := do.MustInvoke[*config.Config](di)
+
+	masterKey, err := loadMasterKey(cfg.SecretStore.MasterKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loadMasterKey: %w", err)
+	}
+
+	service := &Service{
+		cfg:       cfg,
+		queries:   do.MustInvoke[*database.Queries](di),
+		masterKey: masterKey,
+		runtime:   make(map[string]string),
+	}
+
+	if err = service.load(do.MustInvoke[context.Context](di)); err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	return service, nil
+}
+
+func (s *Service) load(ctx context.Context) error {
+	if s.masterKey == nil {
+		return nil
+	}
+
+	secrets, err := s.queries.ListSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("ListSecrets: %w", err)
+	}
+
+	for _, secret := range secrets {
+		value, err := decrypt(s.masterKey, secret.Name, secret.Value)
+		if err != nil {
+			return fmt.Errorf("decrypt secret %s: %w", secret.Name, err)
+		}
+
+		s.runtime[secret.Name] = value
+	}
+
+	return nil
 }
 
 func (s *Service) Fill(text string) string {
@@ -26,5 +81,191 @@ func (s *Service) Fill(text string) string {
 		text = strings.ReplaceAll(text, pattern, content)
 	}
 
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for name, content := range s.runtime {
+		pattern := "%frank(" + name + ")"
+		text = strings.ReplaceAll(text, pattern, content)
+	}
+
+	return text
+}
+
+// Redact replaces every known secret value in the text with its %frank(name) reference
+func (s *Service) Redact(text string) string {
+	type namedValue struct {
+		name  string
+		value string
+	}
+
+	s.mu.RLock()
+
+	values := make([]namedValue, 0, len(s.cfg.Secrets)+len(s.runtime))
+
+	for name, value := range s.cfg.Secrets {
+		values = append(values, namedValue{name: name, value: value})
+	}
+
+	for name, value := range s.runtime {
+		values = append(values, namedValue{name: name, value: value})
+	}
+
+	s.mu.RUnlock()
+
+	// longer values go first, so that a secret containing another one is replaced as a whole
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i].value) != len(values[j].value) {
+			return len(values[i].value) > len(values[j].value)
+		}
+
+		return values[i].name < values[j].name
+	})
+
+	for _, v := range values {
+		if len(v.value) < minRedactLength {
+			continue
+		}
+
+		text = strings.ReplaceAll(text, v.value, "%frank("+v.name+")")
+	}
+
 	return text
 }
+
+// Names returns the sorted names of all available secrets
+func (s *Service) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.cfg.Secrets)+len(s.runtime))
+
+	for name := range s.cfg.Secrets {
+		names = append(names, name)
+	}
+
+	for name := range s.runtime {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// RuntimeNames returns the sorted names of secrets stored in the database
+func (s *Service) RuntimeNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.runtime))
+
+	for name := range s.runtime {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Set encrypts and stores a runtime secret. Secrets defined in config can't be overridden.
+func (s *Service) Set(ctx context.Context, name, value string) error {
+	if s.masterKey == nil {
+		return fmt.Errorf("secret store is disabled, master key is not configured")
+	}
+
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid secret name, must match %s", nameRegexp.String())
+	}
+
+	if _, ok := s.cfg.Secrets[name]; ok {
+		return fmt.Errorf("secret %s is defined in config", name)
+	}
+
+	if value == "" {
+		return fmt.Errorf("secret value is empty")
+	}
+
+	encrypted, err := encrypt(s.masterKey, name, value)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.queries.UpsertSecret(ctx, database.UpsertSecretParams{
+		Name:    name,
+		Value:   encrypted,
+		Updated: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("UpsertSecret: %w", err)
+	}
+
+	s.runtime[name] = value
+
+	return nil
+}
+
+func (s *Service) Delete(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.runtime[name]; !ok {
+		return fmt.Errorf("runtime secret %s not found", name)
+	}
+
+	// config knowledge entries fail startup without their secrets, the bot couldn't be used to set it again
+	if _, ok := s.cfg.Secrets[name]; !ok {
+		if entries := s.requiringEntries(name); len(entries) > 0 {
+			return fmt.Errorf("secret %s is required by config knowledge entries: %s", name, strings.Join(entries, ", "))
+		}
+	}
+
+	if err := s.queries.DeleteSecret(ctx, name); err != nil {
+		return fmt.Errorf("DeleteSecret: %w", err)
+	}
+
+	delete(s.runtime, name)
+
+	return nil
+}
+
+// requiringEntries lists the config knowledge entries which declare the secret
+func (s *Service) requiringEntries(name string) []string {
+	var entries []string
+
+	for entryName, entry := range s.cfg.Knowledge {
+		if slices.Contains(entry.Secrets, name) {
+			entries = append(entries, entryName)
+		}
+	}
+
+	sort.Strings(entries)
+
+	return entries
+}
+
+// Enabled reports whether the master key is configured
+func (s *Service) Enabled() bool {
+	return s.masterKey != nil
+}
+
+// Seal encrypts a value of another service with the master key. The associated data binds
+// the ciphertext to its owner, so Open must be called with the same one.
+func (s *Service) Seal(associated, value string) ([]byte, error) {
+	if s.masterKey == nil {
+		return nil, fmt.Errorf("secret store is disabled, master key is not configured")
+	}
+
+	return encrypt(s.masterKey, associated, value)
+}
+
+func (s *Service) Open(associated string, data []byte) (string, error) {
+	if s.masterKey == nil {
+		return "", fmt.Errorf("secret store is disabled, master key is not configured")
+	}
+
+	return decrypt(s.masterKey, associated, data)
+}
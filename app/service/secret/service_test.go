@@ -1,10 +1,15 @@
 package secret
 
 import (
+	"context"
+	"frank/app/dto"
 	"frank/pkg/config"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func TestService_Fill(t *testing.T) {
@@ -126,3 +131,168 @@ func TestService_Fill(t *testing.T) {
 		})
 	}
 }
+
+func TestService_FillRuntime(t *testing.T) {
+	service := &Service{
+		cfg: &config.Config{
+			Secrets: map[string]string{
+				"CONFIG_TOKEN": "from_config",
+			},
+		},
+		runtime: map[string]string{
+			"RUNTIME_TOKEN": "from_db",
+		},
+	}
+
+	assert.Equal(t, "from_config from_db", service.Fill("%frank(CONFIG_TOKEN) %frank(RUNTIME_TOKEN)"))
+	assert.Equal(t, []string{"CONFIG_TOKEN", "RUNTIME_TOKEN"}, service.Names())
+	assert.Equal(t, []string{"RUNTIME_TOKEN"}, service.RuntimeNames())
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := decodeMasterKey("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	require.NoError(t, err)
+
+	encrypted, err := encrypt(key, "API_KEY", "secret123")
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "secret123")
+
+	decrypted, err := decrypt(key, "API_KEY", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "secret123", decrypted)
+
+	_, err = decrypt(key, "OTHER_NAME", encrypted)
+	require.Error(t, err, "ciphertext must be bound to the secret name")
+
+	_, err = decodeMasterKey("too short")
+	require.Error(t, err)
+}
+
+func TestService_Redact(t *testing.T) {
+	tests := []struct {
+		name     string
+		secrets  map[string]string
+		runtime  map[string]string
+		input    string
+		expected string
+	}{
+		{
+			name:     "redact config secret",
+			secrets:  map[string]string{"API_KEY": "secret123"},
+			input:    `{"token": "secret123"}`,
+			expected: `{"token": "%frank(API_KEY)"}`,
+		},
+		{
+			name:     "redact runtime secret",
+			runtime:  map[string]string{"SESSION": "abcdef"},
+			input:    "Cookie: session=abcdef; abcdef",
+			expected: "Cookie: session=%frank(SESSION); %frank(SESSION)",
+		},
+		{
+			name:     "longer secret containing another one wins",
+			secrets:  map[string]string{"SHORT": "token", "LONG": "token_long"},
+			input:    "token_long token",
+			expected: "%frank(LONG) %frank(SHORT)",
+		},
+		{
+			name:     "too short values are kept",
+			secrets:  map[string]string{"PIN": "12", "EMPTY": ""},
+			input:    "12 apples",
+			expected: "12 apples",
+		},
+		{
+			name:     "nil secrets",
+			input:    "nothing to redact",
+			expected: "nothing to redact",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{
+				cfg:     &config.Config{Secrets: tt.secrets},
+				runtime: tt.runtime,
+			}
+
+			assert.Equal(t, tt.expected, service.Redact(tt.input))
+		})
+	}
+}
+
+func TestService_CheckScope(t *testing.T) {
+	cfg := &config.Config{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+secretScopes:
+  GITHUB_TOKEN:
+    hosts: [api.github.com]
+    locations: [header]
+  WILDCARD:
+    hosts: ["*.example.com"]
+  PREFIX:
+    hosts: ["https://api.telegram.org/bot"]
+    locations: [path]
+  BASE:
+    hosts: ["https://api.example.org"]
+`), cfg))
+
+	service := &Service{cfg: cfg}
+
+	tests := []struct {
+		name     string
+		text     string
+		target   string
+		location dto.SecretLocation
+		allowed  bool
+	}{
+		{"unscoped secret", "%frank(OTHER)", "https://attacker.com", dto.BodySecretLocation, true},
+		{"allowed host and location", "token %frank(GITHUB_TOKEN)", "https://api.github.com/user", dto.HeaderSecretLocation, true},
+		{"host is case insensitive", "%frank(GITHUB_TOKEN)", "https://API.GitHub.com/user", dto.HeaderSecretLocation, true},
+		{"forbidden host", "token %frank(GITHUB_TOKEN)", "https://attacker.com/", dto.HeaderSecretLocation, false},
+		{"forbidden location", "%frank(GITHUB_TOKEN)", "https://api.github.com/user", dto.QuerySecretLocation, false},
+		{"wildcard subdomain", "%frank(WILDCARD)", "https://a.b.example.com/", dto.QuerySecretLocation, true},
+		{"wildcard does not match lookalike", "%frank(WILDCARD)", "https://badexample.com/", dto.QuerySecretLocation, false},
+		{"url prefix", "/bot%frank(PREFIX)/getMe", "https://api.telegram.org/bot123:abc/getMe", dto.PathSecretLocation, true},
+		{"url prefix other scheme", "/bot%frank(PREFIX)", "http://api.telegram.org/bot123", dto.PathSecretLocation, false},
+		{"url prefix exact host", "%frank(BASE)", "https://api.example.org/v1", dto.BodySecretLocation, true},
+		{"url prefix lookalike host", "%frank(BASE)", "https://api.example.org.attacker.com/", dto.BodySecretLocation, false},
+		{"any violation rejects", "%frank(OTHER) %frank(GITHUB_TOKEN)", "https://attacker.com", dto.HeaderSecretLocation, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := url.Parse(tt.target)
+			require.NoError(t, err)
+
+			err = service.CheckScope(tt.text, target, tt.location)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestService_Delete_RequiredByConfigKnowledge(t *testing.T) {
+	cfg := &config.Config{
+		Secrets: map[string]string{"shared_token": "from_config"},
+		Knowledge: map[string]config.KnowledgeEntry{
+			"steam_api":   {Content: "Call Steam", Secrets: []string{"steam_session_id", "shared_token"}},
+			"steam_trade": {Content: "Trade on Steam", Secrets: []string{"steam_session_id"}},
+		},
+	}
+
+	service := &Service{
+		cfg: cfg,
+		runtime: map[string]string{
+			"steam_session_id": "abc",
+			"shared_token":     "from_db",
+		},
+	}
+
+	err := service.Delete(context.Background(), "steam_session_id")
+	assert.EqualError(t, err, "secret steam_session_id is required by config knowledge entries: steam_api, steam_trade")
+	assert.Contains(t, service.RuntimeNames(), "steam_session_id")
+
+	assert.Equal(t, []string(nil), service.requiringEntries("unused"))
+}
@@ -0,0 +1,52 @@
+package summary
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// splitChunks splits text into chunks of about size tokens, estimated the same way as budget.EstimateTokens.
+// Chunks end at line breaks unless a line takes more than half of a chunk.
+func splitChunks(text string, size int) []string {
+	var chunks []string
+
+	start, lastBreak := 0, -1
+	var tokens, breakTokens float64
+
+	for i, r := range text {
+		cost := 0.5
+		if r < utf8.RuneSelf {
+			cost = 0.25
+		}
+
+		if tokens+cost > float64(size) {
+			end := i
+			if lastBreak > start && breakTokens > float64(size)/2 {
+				end = lastBreak
+			}
+
+			chunks = append(chunks, text[start:end])
+
+			// the part after the break is carried over to the next chunk
+			if end == i {
+				tokens = 0
+			} else {
+				tokens -= breakTokens
+			}
+
+			start, lastBreak = end, -1
+		}
+
+		tokens += cost
+
+		if r == '\n' {
+			lastBreak, breakTokens = i+1, tokens
+		}
+	}
+
+	if strings.TrimSpace(text[start:]) != "" {
+		chunks = append(chunks, text[start:])
+	}
+
+	return chunks
+}
@@ -0,0 +1,36 @@
+package summary
+
+import (
+	"frank/pkg/budget"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitChunks(t *testing.T) {
+	t.Run("short text is a single chunk", func(t *testing.T) {
+		assert.Equal(t, []string{"hello\nworld"}, splitChunks("hello\nworld", 100))
+	})
+
+	t.Run("chunks end at line breaks and keep all of the text", func(t *testing.T) {
+		text := strings.Repeat("a line of forty characters of some text\n", 100)
+
+		chunks := splitChunks(text, 100)
+
+		assert.Equal(t, text, strings.Join(chunks, ""))
+		for _, chunk := range chunks[:len(chunks)-1] {
+			assert.True(t, strings.HasSuffix(chunk, "\n"))
+			assert.LessOrEqual(t, budget.EstimateTokens(chunk), 100)
+		}
+	})
+
+	t.Run("a single long line is cut", func(t *testing.T) {
+		text := strings.Repeat("б", 1000)
+
+		chunks := splitChunks(text, 100)
+
+		assert.Len(t, chunks, 5)
+		assert.Equal(t, text, strings.Join(chunks, ""))
+	})
+}
@@ -0,0 +1,150 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"frank/app/client/bothub"
+	"frank/app/service/secret"
+	"frank/pkg/budget"
+	"frank/pkg/config"
+	"log/slog"
+	"strings"
+	"sync"
+
+	_ "embed"
+
+	"github.com/samber/do"
+)
+
+//go:embed MAP_PROMPT
+var mapPromptTemplate string
+
+//go:embed REDUCE_PROMPT
+var reducePromptTemplate string
+
+// chunks summarized at the same time
+var parallelism = 4
+
+// summaries of summaries are made at most this many times, then they are joined as is
+var maxReduceDepth = 3
+
+// Service summarizes oversized command results with a cheaper model, so that they fit the next reasoning step
+type Service struct {
+	cfg           *config.Config
+	bothubClient  *bothub.Client
+	secretService *secret.Service
+}
+
+func New(di *do.Injector) (*Service, error) {
+	return &Service{
+		cfg:           do.MustInvoke[*config.Config](di),
+		bothubClient:  do.MustInvoke[*bothub.Client](di),
+		secretService: do.MustInvoke[*secret.Service](di),
+	}, nil
+}
+
+// Summarize returns a summary of the text with respect to the task, or the text itself and false if it is short enough.
+// Chunks of the text are summarized separately, then the summaries are combined.
+func (s *Service) Summarize(ctx context.Context, text, task string) (string, bool, error) {
+	if budget.EstimateTokens(text) <= *s.cfg.Summarization.Threshold {
+		return text, false, nil
+	}
+
+	summary, err := s.summarize(ctx, s.secretService.Redact(text), s.secretService.Redact(task), 0)
+	if err != nil {
+		return "", false, err
+	}
+
+	return summary, true, nil
+}
+
+func (s *Service) summarize(ctx context.Context, text, task string, depth int) (string, error) {
+	chunks := splitChunks(text, s.cfg.Summarization.ChunkSize)
+
+	summaries, err := s.mapChunks(ctx, chunks, task)
+	if err != nil {
+		return "", err
+	}
+
+	if len(summaries) == 1 {
+		return summaries[0], nil
+	}
+
+	var builder strings.Builder
+
+	for i, summary := range summaries {
+		builder.WriteString(fmt.Sprintf("## Part %d of %d\n%s\n\n", i+1, len(summaries), summary))
+	}
+
+	combined := builder.String()
+
+	if budget.EstimateTokens(combined) > s.cfg.Summarization.ChunkSize {
+		if depth+1 >= maxReduceDepth {
+			slog.WarnContext(ctx, "Summaries are still too long, joining them as is",
+				slog.Int("parts", len(summaries)),
+			)
+
+			return combined, nil
+		}
+
+		return s.summarize(ctx, combined, task, depth+1)
+	}
+
+	summary, err := s.process(ctx, strings.ReplaceAll(reducePromptTemplate, "{task}", task), combined)
+	if err != nil {
+		return "", fmt.Errorf("reduce %d summaries: %w", len(summaries), err)
+	}
+
+	return summary, nil
+}
+
+func (s *Service) mapChunks(ctx context.Context, chunks []string, task string) ([]string, error) {
+	summaries := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, parallelism)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			systemPrompt := mapPromptTemplate
+			systemPrompt = strings.ReplaceAll(systemPrompt, "{part}", fmt.Sprintf("%d of %d", i+1, len(chunks)))
+			systemPrompt = strings.ReplaceAll(systemPrompt, "{task}", task)
+
+			summaries[i], errs[i] = s.process(ctx, systemPrompt, chunk)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("summarize part %d of %d: %w", i+1, len(chunks), errs[i])
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summaries, nil
+}
+
+func (s *Service) process(ctx context.Context, systemPrompt, text string) (string, error) {
+	output, err := s.bothubClient.Process(ctx, bothub.Prompt{
+		SystemText: systemPrompt,
+		UserText:   text,
+		Model:      s.cfg.Summarization.Model,
+	})
+	if err != nil {
+		return "", fmt.Errorf("bothubClient.Process: %w", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
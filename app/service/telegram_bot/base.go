@@ -23,9 +23,15 @@ func (s *Service) handleMessage(ctx context.Context, msg *models.Message) {
 		return
 	}
 
-	switch strings.TrimSpace(msg.Text) {
+	command, args, _ := strings.Cut(strings.TrimSpace(msg.Text), " ")
+
+	switch command {
 	case "/cancel":
 		s.handleCancel(ctx)
+	case "/secret":
+		s.handleSecret(ctx, msg.ID, args)
+	case "/memories":
+		s.handleMemories(ctx, args)
 	default:
 		s.handleUnknownMessage(ctx, msg.ID, msg.Text)
 	}
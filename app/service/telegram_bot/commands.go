@@ -2,7 +2,13 @@ package telegram_bot
 
 import (
 	"context"
+	"fmt"
+	"frank/app/dto"
+	"log/slog"
+	"strconv"
 	"strings"
+
+	"github.com/go-telegram/bot"
 )
 
 func (s *Service) handleCancel(_ context.Context) {
@@ -15,6 +21,109 @@ func (s *Service) handleUnknownMessage(_ context.Context, messageID int, text st
 		return
 	}
 
-	newPrompt := s.promptManager.CreatePrompt(messageID, text)
+	newPrompt := s.promptManager.CreatePrompt(dto.TelegramChannel, messageID, text)
 	s.reasonService.Handle(newPrompt)
 }
+
+func (s *Service) handleSecret(ctx context.Context, messageID int, args string) {
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+
+	switch subcommand {
+	case "set":
+		// the message contains the secret value, so it must not stay in the chat history
+		if _, err := s.tgBot.DeleteMessage(ctx, &bot.DeleteMessageParams{
+			ChatID:    s.cfg.Telegram.ChatID,
+			MessageID: messageID,
+		}); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete secret message",
+				slog.Any("error", err),
+			)
+		}
+
+		name, value, _ := strings.Cut(strings.TrimSpace(rest), " ")
+
+		if err := s.secretService.Set(ctx, name, strings.TrimSpace(value)); err != nil {
+			s.replyService.Reply(ctx, "Failed to set secret: "+err.Error())
+			return
+		}
+
+		s.replyService.Reply(ctx, "Secret '"+name+"' saved")
+	case "list":
+		names := s.secretService.RuntimeNames()
+		if len(names) == 0 {
+			s.replyService.Reply(ctx, "No runtime secrets")
+			return
+		}
+
+		s.replyService.Reply(ctx, "Runtime secrets: "+strings.Join(names, ", "))
+	case "delete":
+		name := strings.TrimSpace(rest)
+
+		if err := s.secretService.Delete(ctx, name); err != nil {
+			s.replyService.Reply(ctx, "Failed to delete secret: "+err.Error())
+			return
+		}
+
+		s.replyService.Reply(ctx, "Secret '"+name+"' deleted")
+	default:
+		s.replyService.Reply(ctx, "Usage: /secret set <name> <value> | /secret list | /secret delete <name>")
+	}
+}
+
+func (s *Service) handleMemories(ctx context.Context, args string) {
+	subcommand, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+
+	switch subcommand {
+	case "", "list":
+		memories, err := s.memoryService.List(ctx)
+		if err != nil {
+			s.replyService.Reply(ctx, "Failed to list memories: "+err.Error())
+			return
+		}
+
+		if len(memories) == 0 {
+			s.replyService.Reply(ctx, "No memories")
+			return
+		}
+
+		var builder strings.Builder
+
+		for _, memory := range memories {
+			builder.WriteString(fmt.Sprintf("#%d %s (%.0f%%)\n", memory.ID, memory.Content, memory.Confidence*100))
+		}
+
+		s.replyService.Reply(ctx, strings.TrimSpace(builder.String()))
+	case "edit":
+		rawID, content, _ := strings.Cut(strings.TrimSpace(rest), " ")
+
+		id, err := strconv.ParseInt(strings.TrimPrefix(rawID, "#"), 10, 64)
+		if err != nil {
+			s.replyService.Reply(ctx, "Invalid memory id: "+rawID)
+			return
+		}
+
+		if err = s.memoryService.Update(ctx, id, content); err != nil {
+			s.replyService.Reply(ctx, "Failed to edit memory: "+err.Error())
+			return
+		}
+
+		s.replyService.Reply(ctx, fmt.Sprintf("Memory #%d updated", id))
+	case "delete":
+		rawID := strings.TrimSpace(rest)
+
+		id, err := strconv.ParseInt(strings.TrimPrefix(rawID, "#"), 10, 64)
+		if err != nil {
+			s.replyService.Reply(ctx, "Invalid memory id: "+rawID)
+			return
+		}
+
+		if err = s.memoryService.Delete(ctx, id); err != nil {
+			s.replyService.Reply(ctx, "Failed to delete memory: "+err.Error())
+			return
+		}
+
+		s.replyService.Reply(ctx, fmt.Sprintf("Memory #%d deleted", id))
+	default:
+		s.replyService.Reply(ctx, "Usage: /memories [list] | /memories edit <id> <text> | /memories delete <id>")
+	}
+}
@@ -2,15 +2,21 @@ package telegram_bot
 
 import (
 	"context"
+	"fmt"
+	"frank/app/service/http_server"
+	"frank/app/service/memory"
 	"frank/app/service/prompt_manager"
 	"frank/app/service/reason"
+	"frank/app/service/secret"
 	"frank/app/service/telegram_reply"
 	"frank/pkg/config"
 	"frank/pkg/database"
 	"log/slog"
+	"net/url"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/samber/do"
 )
 
@@ -21,33 +27,66 @@ type Service struct {
 	replyService  *telegram_reply.Service
 	reasonService *reason.Service
 	promptManager *prompt_manager.Service
+	secretService *secret.Service
+	memoryService *memory.Service
 }
 
 func New(di *do.Injector) (*Service, error) {
+	cfg := do.MustInvoke[*config.Config](di)
 	tgBot := do.MustInvoke[*bot.Bot](di)
 
 	service := &Service{
-		cfg:           do.MustInvoke[*config.Config](di),
+		cfg:           cfg,
 		tgBot:         tgBot,
 		queries:       do.MustInvoke[*database.Queries](di),
 		replyService:  do.MustInvoke[*telegram_reply.Service](di),
 		reasonService: do.MustInvoke[*reason.Service](di),
 		promptManager: do.MustInvoke[*prompt_manager.Service](di),
+		secretService: do.MustInvoke[*secret.Service](di),
+		memoryService: do.MustInvoke[*memory.Service](di),
 	}
 
 	tgBot.RegisterHandlerMatchFunc(func(update *models.Update) bool {
 		return true
 	}, service.handleUpdates)
 
+	if service.WebhookEnabled() {
+		webhookURL, err := url.Parse(cfg.Telegram.Webhook.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parse webhook url: %w", err)
+		}
+
+		webhookPath := webhookURL.Path
+		if webhookPath == "" {
+			webhookPath = "/"
+		}
+
+		httpServer := do.MustInvoke[*http_server.Service](di)
+		httpServer.App().Post(webhookPath, adaptor.HTTPHandlerFunc(tgBot.WebhookHandler()))
+	}
+
 	return service, nil
 }
 
+// WebhookEnabled reports whether updates are received through the webhook instead of long polling
+func (s *Service) WebhookEnabled() bool {
+	return s.cfg.Telegram.Webhook.URL != ""
+}
+
 func (s *Service) initCommands(ctx context.Context) {
 	cmds := []models.BotCommand{
 		{
 			Command:     "/cancel",
 			Description: "Отменить текущее действие",
 		},
+		{
+			Command:     "/secret",
+			Description: "Секреты: set <name> <value> | list | delete <name>",
+		},
+		{
+			Command:     "/memories",
+			Description: "Память: list | edit <id> <text> | delete <id>",
+		},
 	}
 
 	if _, err := s.tgBot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
@@ -59,6 +98,36 @@ func (s *Service) initCommands(ctx context.Context) {
 	}
 }
 
+func (s *Service) initWebhook(ctx context.Context) {
+	if !s.WebhookEnabled() {
+		// telegram refuses getUpdates while a webhook is set
+		if _, err := s.tgBot.DeleteWebhook(ctx, &bot.DeleteWebhookParams{}); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete webhook",
+				slog.Any("error", err),
+			)
+		}
+
+		return
+	}
+
+	if _, err := s.tgBot.SetWebhook(ctx, &bot.SetWebhookParams{
+		URL:         s.cfg.Telegram.Webhook.URL,
+		SecretToken: s.cfg.Telegram.Webhook.SecretToken,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to set webhook",
+			slog.String("url", s.cfg.Telegram.Webhook.URL),
+			slog.Any("error", err),
+		)
+	}
+}
+
 func (s *Service) Run(ctx context.Context) {
+	s.initWebhook(ctx)
 	s.initCommands(ctx)
+
+	if s.WebhookEnabled() {
+		s.tgBot.StartWebhook(ctx)
+	} else {
+		s.tgBot.Start(ctx)
+	}
 }
@@ -2,6 +2,9 @@ package telegram_reply
 
 import (
 	"context"
+	"frank/app/dto"
+	"frank/app/service/prompt_manager"
+	"frank/app/service/reply"
 	"frank/pkg/config"
 	"frank/pkg/util"
 
@@ -10,15 +13,20 @@ import (
 	"github.com/samber/do"
 )
 
+var _ reply.Replier = (*Service)(nil)
+var _ prompt_manager.Listener = (*Service)(nil)
+
 type Service struct {
-	cfg   *config.Config
-	tgBot *bot.Bot
+	appCtx context.Context
+	cfg    *config.Config
+	tgBot  *bot.Bot
 }
 
 func New(di *do.Injector) (*Service, error) {
 	return &Service{
-		cfg:   do.MustInvoke[*config.Config](di),
-		tgBot: do.MustInvoke[*bot.Bot](di),
+		appCtx: do.MustInvoke[context.Context](di),
+		cfg:    do.MustInvoke[*config.Config](di),
+		tgBot:  do.MustInvoke[*bot.Bot](di),
 	}, nil
 }
 
@@ -58,3 +66,19 @@ func (s *Service) SetReaction(ctx context.Context, messageID int, emoji string)
 		IsBig: nil,
 	})
 }
+
+func (s *Service) PromptStepStarted(prompt dto.Prompt, step int) {
+	if prompt.Channel != dto.TelegramChannel || step != 1 {
+		return
+	}
+
+	s.SetReaction(s.appCtx, prompt.MessageID, "👀")
+}
+
+func (s *Service) PromptFinished(prompt dto.Prompt, _ bool) {
+	if prompt.Channel != dto.TelegramChannel {
+		return
+	}
+
+	s.SetReaction(s.appCtx, prompt.MessageID, "👍")
+}
@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"frank/app/dto"
+	"frank/app/service/http_server"
+	"frank/app/service/prompt_manager"
+	"frank/app/service/reason"
+	"frank/pkg/config"
+	"frank/pkg/database"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+	"github.com/samber/do"
+)
+
+const routePrefix = "/hooks/"
+
+var pathRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Service turns incoming webhook calls into prompts. Webhooks come from the config or are created at runtime.
+type Service struct {
+	cfg           *config.Config
+	queries       *database.Queries
+	reasonService *reason.Service
+	promptManager *prompt_manager.Service
+
+	configWebhooks map[string]database.Webhook
+}
+
+func New(di *do.Injector) (*Service, error) {
+	cfg := do.MustInvoke[*config.Config](di)
+
+	configWebhooks := make(map[string]database.Webhook, len(cfg.Webhooks))
+
+	for _, hook := range cfg.Webhooks {
+		if !pathRegexp.MatchString(hook.Path) {
+			return nil, fmt.Errorf("invalid webhook path: %s", hook.Path)
+		}
+
+		configWebhooks[hook.Path] = database.Webhook{
+			Path:    hook.Path,
+			Secret:  hook.Secret,
+			Prompt:  hook.Prompt,
+			Created: time.Time{},
+		}
+	}
+
+	service := &Service{
+		cfg:            cfg,
+		queries:        do.MustInvoke[*database.Queries](di),
+		reasonService:  do.MustInvoke[*reason.Service](di),
+		promptManager:  do.MustInvoke[*prompt_manager.Service](di),
+		configWebhooks: configWebhooks,
+	}
+
+	app := do.MustInvoke[*http_server.Service](di).App()
+	app.Post(routePrefix+":path", service.handleWebhook)
+
+	return service, nil
+}
+
+// CreateWebhook persists a new webhook. A random secret is generated when none is given.
+func (s *Service) CreateWebhook(ctx context.Context, path, secret, prompt string) (database.Webhook, error) {
+	if !pathRegexp.MatchString(path) {
+		return database.Webhook{}, fmt.Errorf("invalid path, must match %s", pathRegexp.String())
+	}
+
+	if _, ok := s.configWebhooks[path]; ok {
+		return database.Webhook{}, fmt.Errorf("webhook %s is already defined in config", path)
+	}
+
+	if strings.TrimSpace(prompt) == "" {
+		return database.Webhook{}, fmt.Errorf("prompt is empty")
+	}
+
+	if secret == "" {
+		secretBytes := make([]byte, 16)
+		if _, err := rand.Read(secretBytes); err != nil {
+			return database.Webhook{}, fmt.Errorf("rand.Read: %w", err)
+		}
+
+		secret = hex.EncodeToString(secretBytes)
+	}
+
+	hook := database.Webhook{
+		Path:    path,
+		Secret:  secret,
+		Prompt:  prompt,
+		Created: time.Now(),
+	}
+
+	if err := s.queries.CreateWebhook(ctx, database.CreateWebhookParams{
+		Path:    hook.Path,
+		Secret:  hook.Secret,
+		Prompt:  hook.Prompt,
+		Created: hook.Created,
+	}); err != nil {
+		return database.Webhook{}, fmt.Errorf("CreateWebhook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// RoutePath returns the path of the HTTP endpoint that serves the webhook
+func (s *Service) RoutePath(path string) string {
+	return routePrefix + path
+}
+
+func (s *Service) findWebhook(ctx context.Context, path string) (database.Webhook, error) {
+	if hook, ok := s.configWebhooks[path]; ok {
+		return hook, nil
+	}
+
+	hook, err := s.queries.GetWebhook(ctx, path)
+	if err != nil {
+		return database.Webhook{}, fmt.Errorf("GetWebhook: %w", err)
+	}
+
+	return hook, nil
+}
+
+func (s *Service) handleWebhook(c *fiber.Ctx) error {
+	path := c.Params("path")
+
+	hook, err := s.findWebhook(c.UserContext(), path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fiber.NewError(fiber.StatusNotFound, "webhook not found")
+	}
+	if err != nil {
+		slog.Error("Failed to find webhook",
+			slog.String("path", path),
+			slog.Any("error", err),
+		)
+
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to find webhook")
+	}
+
+	if !verifySecret(c, hook.Secret) {
+		slog.Warn("Got webhook call with invalid secret",
+			slog.String("path", path),
+			slog.String("ip", c.IP()),
+		)
+
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid secret")
+	}
+
+	newPrompt := s.promptManager.CreatePrompt(dto.WebhookChannel, 0, renderPrompt(hook.Prompt, string(c.Body())))
+
+	slog.InfoContext(newPrompt.Ctx, "Got webhook call",
+		slog.String("path", path),
+	)
+
+	s.reasonService.Handle(newPrompt)
+
+	return c.SendStatus(fiber.StatusAccepted) //nolint:wrapcheck
+}
+
+func renderPrompt(template, body string) string {
+	if !strings.Contains(template, "{body}") {
+		return template + "\n\n" + body
+	}
+
+	return strings.ReplaceAll(template, "{body}", body)
+}
+
+// verifySecret accepts the secret either as is (header or query) or as a GitHub-style HMAC signature of the body.
+// A webhook without a secret is never accepted.
+func verifySecret(c *fiber.Ctx, secret string) bool {
+	if secret == "" {
+		return false
+	}
+
+	for _, candidate := range []string{c.Get("X-Webhook-Secret"), c.Query("secret")} {
+		if candidate != "" && subtle.ConstantTimeCompare([]byte(candidate), []byte(secret)) == 1 {
+			return true
+		}
+	}
+
+	signature, ok := strings.CutPrefix(c.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return false
+	}
+
+	signatureBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(c.Body())
+
+	return hmac.Equal(signatureBytes, mac.Sum(nil))
+}
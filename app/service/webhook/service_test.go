@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySecret(t *testing.T) {
+	body := `{"action":"opened"}`
+
+	tests := []struct {
+		name     string
+		secret   string
+		query    string
+		headers  map[string]string
+		expected bool
+	}{
+		{"header", "s3cret", "", map[string]string{"X-Webhook-Secret": "s3cret"}, true},
+		{"wrong header", "s3cret", "", map[string]string{"X-Webhook-Secret": "guess"}, false},
+		{"query", "s3cret", "?secret=s3cret", nil, true},
+		{"wrong query", "s3cret", "?secret=guess", nil, false},
+		{"valid signature", "s3cret", "", map[string]string{"X-Hub-Signature-256": sign("s3cret", body)}, true},
+		{"signature with another secret", "s3cret", "", map[string]string{"X-Hub-Signature-256": sign("guess", body)}, false},
+		{"signature without prefix", "s3cret", "", map[string]string{"X-Hub-Signature-256": strings.TrimPrefix(sign("s3cret", body), "sha256=")}, false},
+		{"malformed signature", "s3cret", "", map[string]string{"X-Hub-Signature-256": "sha256=zz"}, false},
+		{"missing", "s3cret", "", nil, false},
+		{"webhook without a secret", "", "?secret=", map[string]string{"X-Webhook-Secret": ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				if verifySecret(c, tt.secret) {
+					return c.SendStatus(fiber.StatusOK)
+				}
+
+				return c.SendStatus(fiber.StatusUnauthorized)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/"+tt.query, strings.NewReader(body))
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, resp.StatusCode == fiber.StatusOK)
+		})
+	}
+}
+
+func TestRenderPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		body     string
+		expected string
+	}{
+		{"body placeholder", "New order: {body}", `{"id":1}`, `New order: {"id":1}`},
+		{"several placeholders", "{body} / {body}", "x", "x / x"},
+		{"body is appended without a placeholder", "Summarize the event", `{"id":1}`, "Summarize the event\n\n{\"id\":1}"},
+		{"empty body", "Check the build", "", "Check the build\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, renderPrompt(tt.template, tt.body))
+		})
+	}
+}
@@ -4,17 +4,32 @@ package main
 import (
 	"context"
 	"frank/app/client/bothub"
+	"frank/app/client/brave"
+	"frank/app/client/searxng"
 	"frank/app/client/yandex"
+	"frank/app/dto"
 	"frank/app/service/act"
+	"frank/app/service/api"
+	"frank/app/service/cache"
+	"frank/app/service/console"
+	"frank/app/service/cookie_jar"
+	"frank/app/service/email"
+	"frank/app/service/http_server"
 	"frank/app/service/knowledge"
+	"frank/app/service/memory"
 	"frank/app/service/prompt_manager"
 	"frank/app/service/reason"
+	"frank/app/service/reply"
 	"frank/app/service/scheduler"
+	"frank/app/service/search"
 	"frank/app/service/secret"
+	"frank/app/service/summary"
 	"frank/app/service/telegram_bot"
 	"frank/app/service/telegram_reply"
+	"frank/app/service/webhook"
 	"frank/pkg/config"
 	"frank/pkg/database"
+	"frank/pkg/egress"
 	"frank/pkg/migration"
 	"frank/pkg/tlog"
 	"log/slog"
@@ -32,8 +47,6 @@ func main() {
 	appCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	exitChan := make(chan struct{})
-
 	di := do.New()
 	do.ProvideValue(di, appCtx)
 
@@ -70,6 +83,12 @@ func main() {
 		log.Fatalf("failed to init schema: %v", err)
 	}
 
+	if cfg.KnowledgeRetrieval.Strategy == "vector" {
+		if err = database.InitVectorSchema(appCtx, dbConn); err != nil {
+			log.Fatalf("failed to init vector schema: %v", err)
+		}
+	}
+
 	do.ProvideValue(di, dbConn)
 
 	queries := database.New(dbConn)
@@ -79,31 +98,86 @@ func main() {
 		log.Fatalf("failed to migrate: %v", err)
 	}
 
-	telegramBot, err := bot.New(cfg.Telegram.Token)
+	egressPolicy, err := egress.NewPolicy(cfg)
 	if err != nil {
-		log.Fatalf("failed to create telegram bot: %v", err)
+		log.Fatalf("egress policy init failed: %v", err)
 	}
-	do.ProvideValue(di, telegramBot)
+	do.ProvideValue(di, egressPolicy)
 
+	do.Provide(di, http_server.New)
 	do.Provide(di, bothub.NewClient)
 	do.Provide(di, yandex.NewClient)
+	do.Provide(di, searxng.NewClient)
+	do.Provide(di, brave.NewClient)
+	do.Provide(di, search.New)
 	do.Provide(di, secret.New)
+	do.Provide(di, cookie_jar.New)
+	do.Provide(di, cache.New)
 	do.Provide(di, knowledge.New)
+	do.Provide(di, memory.New)
+	do.Provide(di, summary.New)
 	do.Provide(di, prompt_manager.New)
-	do.Provide(di, telegram_bot.New)
-	do.Provide(di, telegram_reply.New)
+	do.Provide(di, reply.New)
 	do.Provide(di, reason.New)
+	do.Provide(di, webhook.New)
+	do.Provide(di, email.New)
 	do.Provide(di, act.New)
 	do.Provide(di, scheduler.New)
 
-	go telegramBot.Start(appCtx)
-	defer telegramBot.Close(appCtx)
+	tlog.SetRedactor(do.MustInvoke[*secret.Service](di))
 
-	go do.MustInvoke[*telegram_bot.Service](di).Run(appCtx)
+	if len(os.Args) > 1 && os.Args[1] == "chat" {
+		runChat(appCtx, di)
+	} else {
+		runServer(appCtx, di, cfg)
+	}
+
+	cancel()
+
+	log.Info("Waiting for services to finish...")
+	_ = di.Shutdown()
+}
+
+func runServer(appCtx context.Context, di *do.Injector, cfg *config.Config) {
+	exitChan := make(chan struct{})
+
+	var botOpts []bot.Option
+	if cfg.Telegram.Webhook.SecretToken != "" {
+		botOpts = append(botOpts, bot.WithWebhookSecretToken(cfg.Telegram.Webhook.SecretToken))
+	}
+
+	telegramBot, err := bot.New(cfg.Telegram.Token, botOpts...)
+	if err != nil {
+		log.Fatalf("failed to create telegram bot: %v", err)
+	}
+	do.ProvideValue(di, telegramBot)
+
+	do.Provide(di, telegram_bot.New)
+	do.Provide(di, telegram_reply.New)
+	do.Provide(di, api.New)
+
+	replyService := do.MustInvoke[*reply.Service](di)
+	replyService.Register(dto.TelegramChannel, do.MustInvoke[*telegram_reply.Service](di))
+	replyService.Register(dto.APIChannel, do.MustInvoke[*api.Service](di))
+	replyService.Register(dto.EmailChannel, do.MustInvoke[*email.Service](di))
+
+	promptManager := do.MustInvoke[*prompt_manager.Service](di)
+	promptManager.AddListener(do.MustInvoke[*telegram_reply.Service](di))
+	promptManager.AddListener(do.MustInvoke[*api.Service](di))
+	promptManager.AddListener(do.MustInvoke[*email.Service](di))
+	promptManager.AddListener(do.MustInvoke[*memory.Service](di))
+
+	defer telegramBot.Close(appCtx)
 
 	do.MustInvoke[*reason.Service](di).SetActor(do.MustInvoke[*act.Service](di))
 	do.MustInvoke[*scheduler.Service](di).SetActor(do.MustInvoke[*act.Service](di))
 
+	// every service registering routes must be created before the server starts listening
+	go do.MustInvoke[*telegram_bot.Service](di).Run(appCtx)
+	go do.MustInvoke[*http_server.Service](di).Run(appCtx)
+	go do.MustInvoke[*email.Service](di).Run(appCtx)
+	go do.MustInvoke[*knowledge.Service](di).Run(appCtx)
+
 	if err = do.MustInvoke[*scheduler.Service](di).Start(); err != nil {
 		log.Fatalf("failed to start scheduler: %v", err)
 	}
@@ -121,8 +195,39 @@ func main() {
 	log.Info("Server started")
 
 	<-exitChan
-	cancel()
+}
 
-	log.Info("Waiting for services to finish...")
-	_ = di.Shutdown()
+// runChat replaces telegram with stdin/stdout, so that prompts can be tested locally or scripted.
+// Persisted jobs are not started to avoid firing them twice alongside the deployed instance.
+func runChat(appCtx context.Context, di *do.Injector) {
+	do.Provide(di, console.New)
+	do.Provide(di, console.NewReplier)
+
+	do.MustInvoke[*reply.Service](di).Register(dto.ConsoleChannel, do.MustInvoke[*console.Replier](di))
+	do.MustInvoke[*prompt_manager.Service](di).AddListener(do.MustInvoke[*console.Replier](di))
+	do.MustInvoke[*prompt_manager.Service](di).AddListener(do.MustInvoke[*memory.Service](di))
+
+	do.MustInvoke[*reason.Service](di).SetActor(do.MustInvoke[*act.Service](di))
+	do.MustInvoke[*scheduler.Service](di).SetActor(do.MustInvoke[*act.Service](di))
+
+	go do.MustInvoke[*knowledge.Service](di).Run(appCtx)
+
+	doneChan := make(chan struct{})
+
+	go func() {
+		defer close(doneChan)
+
+		if err := do.MustInvoke[*console.Service](di).Run(appCtx); err != nil {
+			log.Errorf("console failed: %v", err)
+		}
+	}()
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt)
+
+	select {
+	case <-doneChan:
+	case <-sigint:
+		log.Info("Shutting down chat...")
+	}
 }
@@ -0,0 +1,137 @@
+package budget
+
+import (
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// items cut below this size carry too little to be useful, they are dropped instead
+var minTruncatedTokens = 200
+
+// EstimateTokens approximates the token count without a model specific tokenizer.
+// Latin text and code average about 4 characters per token, other scripts like Cyrillic about 2.
+func EstimateTokens(text string) int {
+	var ascii, other int
+
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+
+	return int(math.Ceil(float64(ascii)/4 + float64(other)/2))
+}
+
+// Budget distributes a token limit between parts of a prompt. Parts are added in the order of importance,
+// the ones that don't fit are truncated or dropped and listed in the report.
+type Budget struct {
+	limit int
+	used  int
+
+	truncated []string
+	dropped   []string
+}
+
+func New(limit int) *Budget {
+	return &Budget{limit: limit}
+}
+
+type Report struct {
+	Limit     int
+	Used      int
+	Truncated []string
+	Dropped   []string
+}
+
+func (b *Budget) Remaining() int {
+	return max(b.limit-b.used, 0)
+}
+
+// Reserve counts text which is sent in any case, like the instructions
+func (b *Budget) Reserve(tokens int) {
+	b.used += tokens
+}
+
+// Add takes the whole text if it fits
+func (b *Budget) Add(name, text string) bool {
+	tokens := EstimateTokens(text)
+
+	if tokens > b.Remaining() {
+		b.Drop(name)
+		return false
+	}
+
+	b.used += tokens
+
+	return true
+}
+
+// AddTruncated takes the text, cutting it to at most maxShare of the remaining budget
+func (b *Budget) AddTruncated(name, text string, maxShare float64) (string, bool) {
+	tokens := EstimateTokens(text)
+	available := int(float64(b.Remaining()) * maxShare)
+
+	if tokens <= available {
+		b.used += tokens
+		return text, true
+	}
+
+	if available < minTruncatedTokens {
+		b.Drop(name)
+		return "", false
+	}
+
+	text = Truncate(text, available)
+
+	b.used += EstimateTokens(text)
+	b.truncated = append(b.truncated, name)
+
+	return text, true
+}
+
+// Drop records a part left out without trying to fit it
+func (b *Budget) Drop(name string) {
+	b.dropped = append(b.dropped, name)
+}
+
+func (b *Budget) Report() Report {
+	return Report{
+		Limit:     b.limit,
+		Used:      b.used,
+		Truncated: b.truncated,
+		Dropped:   b.dropped,
+	}
+}
+
+// Truncate cuts the text to about the given number of tokens, marking how much is left out
+func Truncate(text string, tokens int) string {
+	total := EstimateTokens(text)
+	if total <= tokens {
+		return text
+	}
+
+	// room for the marker
+	tokens = max(tokens-20, 0)
+
+	var used float64
+	end := 0
+
+	for i, r := range text {
+		if r < utf8.RuneSelf {
+			used += 0.25
+		} else {
+			used += 0.5
+		}
+
+		if used > float64(tokens) {
+			break
+		}
+
+		end = i + utf8.RuneLen(r)
+	}
+
+	return text[:end] + fmt.Sprintf("\n...[truncated, about %d more tokens]", total-EstimateTokens(text[:end]))
+}
@@ -0,0 +1,37 @@
+package budget
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 3, EstimateTokens("hello world!"))
+	assert.Equal(t, 3, EstimateTokens("привет"))
+}
+
+func TestBudget(t *testing.T) {
+	b := New(1000)
+	b.Reserve(100)
+
+	assert.True(t, b.Add("small", strings.Repeat("a", 400)))
+	assert.Equal(t, 800, b.Remaining())
+
+	text, ok := b.AddTruncated("large", strings.Repeat("b", 8000), 0.5)
+	assert.True(t, ok)
+	assert.Contains(t, text, "...[truncated, about")
+	assert.LessOrEqual(t, EstimateTokens(text), 400)
+
+	assert.False(t, b.Add("too large", strings.Repeat("c", 4000)))
+
+	_, ok = b.AddTruncated("no room", strings.Repeat("d", 4000), 0.1)
+	assert.False(t, ok)
+
+	report := b.Report()
+	assert.Equal(t, []string{"large"}, report.Truncated)
+	assert.Equal(t, []string{"too large", "no room"}, report.Dropped)
+	assert.Equal(t, 1000, report.Limit)
+}
@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"frank/pkg/util"
+	"maps"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
@@ -14,25 +18,173 @@ type Config struct {
 		TelegramChatID string `yaml:"telegramChatID"`
 	} `yaml:"log"`
 
-	Secrets   map[string]string `yaml:"secrets"`
-	Knowledge map[string]string `yaml:"knowledge"`
+	Secrets   map[string]string         `yaml:"secrets"`
+	Knowledge map[string]KnowledgeEntry `yaml:"knowledge" validate:"dive"`
+
+	// KnowledgeChannels may change knowledge with remember and forget. Prompts of other channels
+	// work on untrusted input like webhook payloads and emails, an injected entry would reach every later prompt.
+	KnowledgeChannels []string `yaml:"knowledgeChannels" validate:"dive,oneof=telegram console api webhook email"`
+
+	// KnowledgeDir is a directory of Markdown and YAML files with knowledge entries, reloaded on change
+	KnowledgeDir struct {
+		Path         string        `yaml:"path"`
+		PollInterval time.Duration `yaml:"pollInterval"`
+	} `yaml:"knowledgeDir"`
+
+	KnowledgeRetrieval struct {
+		// llm asks the model to pick entries by name, vector selects chunks by embedding similarity using pgvector,
+		// bm25 ranks chunks by keywords with an in-memory index
+		Strategy string `yaml:"strategy" validate:"omitempty,oneof=llm vector bm25"`
+		// Hybrid combines the strategy with bm25 keyword ranking
+		Hybrid bool `yaml:"hybrid"`
+		// Rerank passes the selected chunks through the llm selector
+		Rerank bool `yaml:"rerank"`
+		// Threshold is the minimum cosine similarity of vector matches, a pointer so that 0 can be set explicitly
+		Threshold *float64 `yaml:"threshold" validate:"omitempty,min=-1,max=1"`
+		Limit     int      `yaml:"limit" validate:"min=0"`
+		// ChunkSize is the maximum chunk length in characters
+		ChunkSize int `yaml:"chunkSize" validate:"min=0"`
+
+		Embeddings struct {
+			// local hashes words into a vector, needs no external service
+			Provider string `yaml:"provider" validate:"omitempty,oneof=local bothub"`
+			// Model is used by bothub, Dimensions by local
+			Model      string `yaml:"model"`
+			Dimensions int    `yaml:"dimensions" validate:"min=0"`
+		} `yaml:"embeddings"`
+	} `yaml:"knowledgeRetrieval"`
+
+	// ContextWindow limits the size of reasoning prompts, parts that don't fit are truncated or left out
+	ContextWindow struct {
+		// Limits are in tokens per model name, other models use DefaultLimit
+		Limits       map[string]int `yaml:"limits" validate:"dive,min=1000"`
+		DefaultLimit int            `yaml:"defaultLimit" validate:"min=0"`
+		// OutputReserve is kept free for the response
+		OutputReserve int `yaml:"outputReserve" validate:"min=0"`
+	} `yaml:"contextWindow"`
+
+	// Memory is extracted from finished prompts in the background and added to the context of next ones
+	Memory struct {
+		// prompts of these channels are analyzed, an empty list disables the extraction
+		Channels      []string `yaml:"channels" validate:"dive,oneof=telegram console api webhook email"`
+		MinConfidence *float64 `yaml:"minConfidence" validate:"omitempty,min=0,max=1"`
+		// Limit is the maximum number of memories added to the context, 0 leaves them out
+		Limit *int `yaml:"limit" validate:"omitempty,min=0"`
+	} `yaml:"memory"`
+
+	// Summarization shortens attachments that are too long to be passed verbatim to the next reasoning step
+	Summarization struct {
+		// attachments above Threshold tokens are summarized in chunks of ChunkSize tokens, 0 summarizes all of them
+		Threshold *int `yaml:"threshold" validate:"omitempty,min=0"`
+		ChunkSize int  `yaml:"chunkSize" validate:"min=0"`
+		// Model should be cheaper than the reasoning one, it reads all of the attachment
+		Model string `yaml:"model"`
+	} `yaml:"summarization"`
+
+	// SecretScopes restrict where secrets may be sent. Secrets without a scope are unrestricted.
+	SecretScopes map[string]struct {
+		// exact hosts, *.example.com wildcards or URL prefixes like https://api.example.com/v1/
+		Hosts     []string `yaml:"hosts"`
+		Locations []string `yaml:"locations" validate:"dive,oneof=header body query path"`
+	} `yaml:"secretScopes" validate:"dive"`
+
+	SecretStore struct {
+		// file with a base64 or hex encoded 32 byte key, FRANK_MASTER_KEY env variable takes precedence
+		MasterKeyFile string `yaml:"masterKeyFile"`
+	} `yaml:"secretStore"`
+
+	HTTP struct {
+		Listen string `yaml:"listen"`
+	} `yaml:"http"`
+
+	// Egress restricts outbound requests made on behalf of the model.
+	// Loopback, private, link-local and other internal ranges are denied unless listed in AllowCIDRs.
+	Egress struct {
+		Schemes    []string `yaml:"schemes" validate:"dive,oneof=http https"`
+		Ports      []int    `yaml:"ports" validate:"dive,min=1,max=65535"`
+		AllowHosts []string `yaml:"allowHosts"`
+		DenyHosts  []string `yaml:"denyHosts"`
+		AllowCIDRs []string `yaml:"allowCIDRs" validate:"dive,cidr"`
+		DenyCIDRs  []string `yaml:"denyCIDRs" validate:"dive,cidr"`
+	} `yaml:"egress"`
+
+	API struct {
+		Tokens []string `yaml:"tokens"`
+	} `yaml:"api"`
+
+	Webhooks []struct {
+		Path string `yaml:"path" validate:"required"`
+		// Secret is required, webhooks start prompts with access to all commands
+		Secret string `yaml:"secret" validate:"required"`
+		Prompt string `yaml:"prompt" validate:"required"`
+	} `yaml:"webhooks" validate:"dive"`
 
 	Telegram struct {
 		Token  string `yaml:"token" validate:"required"`
 		ChatID int64  `yaml:"chatId" validate:"required"`
+
+		Webhook struct {
+			URL         string `yaml:"url" validate:"omitempty,url"`
+			SecretToken string `yaml:"secretToken"`
+		} `yaml:"webhook"`
 	} `yaml:"telegram"`
 
+	Email struct {
+		Address        string   `yaml:"address" validate:"omitempty,email"`
+		AllowedSenders []string `yaml:"allowedSenders"`
+		// AllowedRecipients are the only addresses the send_email command may write to
+		AllowedRecipients []string      `yaml:"allowedRecipients"`
+		PollInterval      time.Duration `yaml:"pollInterval"`
+		// AuthServID identifies the Authentication-Results headers added by the receiving server, e.g. mx.google.com.
+		// Emails are accepted only if it verified the sender with DMARC, DKIM or SPF.
+		AuthServID string `yaml:"authServId"`
+
+		IMAP struct {
+			Host     string `yaml:"host" validate:"omitempty,hostname_port"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+			Mailbox  string `yaml:"mailbox"`
+		} `yaml:"imap"`
+
+		SMTP struct {
+			Host     string `yaml:"host" validate:"omitempty,hostname_port"`
+			Username string `yaml:"username"`
+			Password string `yaml:"password"`
+		} `yaml:"smtp"`
+	} `yaml:"email"`
+
 	Bothub struct {
 		Token string `yaml:"token" validate:"required"`
 	} `yaml:"bothub"`
 
+	// Yandex credentials are required only when yandex is one of the search providers
 	Yandex struct {
-		ServiceAccountID string `yaml:"serviceAccountId" validate:"required"`
-		FolderID         string `yaml:"folderId" validate:"required"`
-		KeyID            string `yaml:"keyId" validate:"required"`
-		Key              string `yaml:"key" validate:"required"`
+		ServiceAccountID string `yaml:"serviceAccountId"`
+		FolderID         string `yaml:"folderId"`
+		KeyID            string `yaml:"keyId"`
+		Key              string `yaml:"key"`
 	} `yaml:"yandex"`
 
+	Cache struct {
+		// memory or postgres
+		Backend string `yaml:"backend" validate:"omitempty,oneof=memory postgres"`
+		// per command name, commands without a TTL are not cached
+		TTL map[string]time.Duration `yaml:"ttl"`
+	} `yaml:"cache"`
+
+	Search struct {
+		// queried together, results are merged with duplicate URLs removed
+		Providers []string `yaml:"providers" validate:"dive,oneof=yandex searxng brave fake"`
+
+		SearXNG struct {
+			URL string `yaml:"url" validate:"omitempty,url"`
+		} `yaml:"searxng"`
+
+		Brave struct {
+			Token string `yaml:"token"`
+		} `yaml:"brave"`
+	} `yaml:"search"`
+
 	DB struct {
 		User     string `yaml:"user" validate:"required"`
 		Pass     string `yaml:"pass" validate:"required"`
@@ -41,6 +193,27 @@ type Config struct {
 	} `yaml:"db"`
 }
 
+// KnowledgeEntry is either a plain string with the content or a mapping with metadata making the recipe executable
+type KnowledgeEntry struct {
+	Content string `yaml:"content"`
+	// Secrets are names of the %frank(name) secrets the entry uses
+	Secrets  []string `yaml:"secrets"`
+	BaseURLs []string `yaml:"baseUrls" validate:"dive,url"`
+	// Examples are complete commands, e.g. http_request JSON objects
+	Examples []string `yaml:"examples"`
+}
+
+func (e *KnowledgeEntry) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		e.Content = node.Value
+		return nil
+	}
+
+	type plain KnowledgeEntry
+
+	return node.Decode((*plain)(e))
+}
+
 func Load() (*Config, error) {
 	data, err := os.ReadFile("config.yaml")
 	if err != nil {
@@ -52,6 +225,21 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse YAML config: %w", err)
 	}
 
+	if result.HTTP.Listen == "" {
+		result.HTTP.Listen = ":8080"
+	}
+
+	if len(result.Egress.Schemes) == 0 {
+		result.Egress.Schemes = []string{"http", "https"}
+	}
+
+	if result.Email.PollInterval == 0 {
+		result.Email.PollInterval = time.Minute
+	}
+	if result.Email.IMAP.Mailbox == "" {
+		result.Email.IMAP.Mailbox = "INBOX"
+	}
+
 	if result.DB.User == "" {
 		result.DB.User = "postgres"
 	}
@@ -65,10 +253,100 @@ func Load() (*Config, error) {
 		result.DB.Database = "frank"
 	}
 
+	if result.Cache.Backend == "" {
+		result.Cache.Backend = "memory"
+	}
+	if result.Cache.TTL == nil {
+		result.Cache.TTL = map[string]time.Duration{
+			"web_search":   time.Hour,
+			"http_request": 10 * time.Minute,
+		}
+	}
+
+	if result.KnowledgeChannels == nil {
+		result.KnowledgeChannels = []string{"telegram", "console"}
+	}
+	if result.KnowledgeDir.PollInterval == 0 {
+		result.KnowledgeDir.PollInterval = 10 * time.Second
+	}
+
+	if result.KnowledgeRetrieval.Strategy == "" {
+		result.KnowledgeRetrieval.Strategy = "llm"
+	}
+	result.KnowledgeRetrieval.Threshold = util.PtrOrDefault(result.KnowledgeRetrieval.Threshold, 0.3)
+	if result.KnowledgeRetrieval.Limit == 0 {
+		result.KnowledgeRetrieval.Limit = 5
+	}
+	if result.KnowledgeRetrieval.ChunkSize == 0 {
+		result.KnowledgeRetrieval.ChunkSize = 1000
+	}
+	if result.KnowledgeRetrieval.Embeddings.Provider == "" {
+		result.KnowledgeRetrieval.Embeddings.Provider = "local"
+	}
+	if result.KnowledgeRetrieval.Embeddings.Dimensions == 0 {
+		result.KnowledgeRetrieval.Embeddings.Dimensions = 512
+	}
+
+	if result.ContextWindow.Limits == nil {
+		result.ContextWindow.Limits = map[string]int{
+			"deepseek-chat-v3-0324": 128000,
+			"deepseek-r1-0528":      128000,
+			"deepseek-r1":           64000,
+		}
+	}
+	if result.ContextWindow.DefaultLimit == 0 {
+		result.ContextWindow.DefaultLimit = 32000
+	}
+	if result.ContextWindow.OutputReserve == 0 {
+		result.ContextWindow.OutputReserve = 8000
+	}
+
+	if result.Memory.Channels == nil {
+		result.Memory.Channels = []string{"telegram", "console"}
+	}
+	result.Memory.MinConfidence = util.PtrOrDefault(result.Memory.MinConfidence, 0.7)
+	result.Memory.Limit = util.PtrOrDefault(result.Memory.Limit, 20)
+
+	result.Summarization.Threshold = util.PtrOrDefault(result.Summarization.Threshold, 4000)
+	if result.Summarization.ChunkSize == 0 {
+		result.Summarization.ChunkSize = 8000
+	}
+	if result.Summarization.Model == "" {
+		result.Summarization.Model = "gpt-4o-mini"
+	}
+
+	if len(result.Search.Providers) == 0 {
+		result.Search.Providers = []string{"yandex"}
+	}
+
 	validate := validator.New(validator.WithRequiredStructEnabled())
 	if err := validate.Struct(result); err != nil {
 		return nil, fmt.Errorf("failed to validate config: %w", err)
 	}
 
+	if slices.Contains(result.Search.Providers, "yandex") &&
+		(result.Yandex.ServiceAccountID == "" || result.Yandex.FolderID == "" || result.Yandex.KeyID == "" || result.Yandex.Key == "") {
+		return nil, fmt.Errorf("failed to validate config: yandex search provider requires serviceAccountId, folderId, keyId and key")
+	}
+
+	if slices.Contains(result.Search.Providers, "searxng") && result.Search.SearXNG.URL == "" {
+		return nil, fmt.Errorf("failed to validate config: searxng search provider requires url")
+	}
+
+	if slices.Contains(result.Search.Providers, "brave") && result.Search.Brave.Token == "" {
+		return nil, fmt.Errorf("failed to validate config: brave search provider requires token")
+	}
+
+	// otherwise nothing but the instructions fits the prompt
+	if result.ContextWindow.DefaultLimit <= result.ContextWindow.OutputReserve {
+		return nil, fmt.Errorf("failed to validate config: contextWindow defaultLimit must be larger than outputReserve %d", result.ContextWindow.OutputReserve)
+	}
+
+	for _, model := range slices.Sorted(maps.Keys(result.ContextWindow.Limits)) {
+		if result.ContextWindow.Limits[model] <= result.ContextWindow.OutputReserve {
+			return nil, fmt.Errorf("failed to validate config: contextWindow limit of %s must be larger than outputReserve %d", model, result.ContextWindow.OutputReserve)
+		}
+	}
+
 	return &result, nil
 }
@@ -18,3 +18,16 @@ func InitSchema(ctx context.Context, dbConn *pgxpool.Pool) error {
 
 	return nil
 }
+
+//go:embed vector_schema.sql
+var vectorSchema string
+
+// InitVectorSchema creates the tables used for vector similarity search, the pgvector extension must be available
+func InitVectorSchema(ctx context.Context, dbConn *pgxpool.Pool) error {
+	_, err := dbConn.Exec(ctx, vectorSchema)
+	if err != nil {
+		return fmt.Errorf("sql exec error: %w", err)
+	}
+
+	return nil
+}
@@ -8,8 +8,53 @@ import (
 	"time"
 
 	"frank/app/dto"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
 )
 
+type CacheEntry struct {
+	Key     string
+	Value   []byte
+	Expires time.Time
+}
+
+type Cookie struct {
+	Session  string
+	Domain   string
+	Path     string
+	Name     string
+	Value    []byte
+	HostOnly bool
+	Secure   bool
+	HttpOnly bool
+	Expires  *time.Time
+	Updated  time.Time
+}
+
+type Knowledge struct {
+	Name    string
+	Content string
+	Created time.Time
+	Updated time.Time
+}
+
+type KnowledgeChunk struct {
+	Name      string
+	Chunk     int32
+	Content   string
+	Hash      string
+	Embedding *pgvector.Vector
+}
+
+type Memory struct {
+	ID             int64
+	Content        string
+	SourcePromptID uuid.UUID
+	Confidence     float32
+	Created        time.Time
+	Updated        time.Time
+}
+
 type Migration struct {
 	ID      string
 	Applied time.Time
@@ -20,3 +65,16 @@ type ScheduledJob struct {
 	Created time.Time
 	Data    dto.ScheduledJobData
 }
+
+type Secret struct {
+	Name    string
+	Value   []byte
+	Updated time.Time
+}
+
+type Webhook struct {
+	Path    string
+	Secret  string
+	Prompt  string
+	Created time.Time
+}
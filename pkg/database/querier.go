@@ -6,6 +6,7 @@ package database
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
@@ -13,6 +14,16 @@ type Querier interface {
 	//
 	//  SELECT COUNT(*) FROM scheduled_jobs
 	CountScheduledJobs(ctx context.Context) (int64, error)
+	//CreateKnowledgeChunk
+	//
+	//  INSERT INTO knowledge_chunks (name, chunk, content, hash, embedding)
+	//  VALUES ($1, $2, $3, $4, $5)
+	CreateKnowledgeChunk(ctx context.Context, arg CreateKnowledgeChunkParams) error
+	//CreateMemory
+	//
+	//  INSERT INTO memories (content, source_prompt_id, confidence, created, updated)
+	//  VALUES ($1, $2, $3, $4, $4) RETURNING id
+	CreateMemory(ctx context.Context, arg CreateMemoryParams) (int64, error)
 	//CreateMigration
 	//
 	//  INSERT INTO migration (id, applied)
@@ -23,11 +34,51 @@ type Querier interface {
 	//  INSERT INTO scheduled_jobs (name, created, data)
 	//  VALUES ($1, $2, $3)
 	CreateScheduledJob(ctx context.Context, arg CreateScheduledJobParams) error
+	//CreateWebhook
+	//
+	//  INSERT INTO webhooks (path, secret, prompt, created)
+	//  VALUES ($1, $2, $3, $4)
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) error
+	//DeleteCookie
+	//
+	//  DELETE FROM cookies
+	//  WHERE session = $1 AND domain = $2 AND path = $3 AND name = $4
+	DeleteCookie(ctx context.Context, arg DeleteCookieParams) error
+	//DeleteExpiredCacheEntries
+	//
+	//  DELETE FROM cache_entries
+	//  WHERE expires <= $1
+	DeleteExpiredCacheEntries(ctx context.Context, expires time.Time) error
+	//DeleteKnowledge
+	//
+	//  DELETE FROM knowledge
+	//  WHERE name = $1
+	DeleteKnowledge(ctx context.Context, name string) (int64, error)
+	//DeleteKnowledgeChunks
+	//
+	//  DELETE FROM knowledge_chunks
+	//  WHERE name = $1
+	DeleteKnowledgeChunks(ctx context.Context, name string) error
+	//DeleteMemory
+	//
+	//  DELETE FROM memories
+	//  WHERE id = $1
+	DeleteMemory(ctx context.Context, id int64) (int64, error)
 	//DeleteScheduledJob
 	//
 	//  DELETE FROM scheduled_jobs
 	//  WHERE name = $1
 	DeleteScheduledJob(ctx context.Context, name string) error
+	//DeleteSecret
+	//
+	//  DELETE FROM secrets
+	//  WHERE name = $1
+	DeleteSecret(ctx context.Context, name string) error
+	//GetCacheEntry
+	//
+	//  SELECT value FROM cache_entries
+	//  WHERE key = $1 AND expires > $2
+	GetCacheEntry(ctx context.Context, arg GetCacheEntryParams) ([]byte, error)
 	//GetMigrations
 	//
 	//  SELECT id, applied
@@ -39,11 +90,83 @@ type Querier interface {
 	//  SELECT name, created, data FROM scheduled_jobs
 	//  WHERE name = $1
 	GetScheduledJob(ctx context.Context, name string) (ScheduledJob, error)
+	//GetWebhook
+	//
+	//  SELECT path, secret, prompt, created FROM webhooks
+	//  WHERE path = $1
+	GetWebhook(ctx context.Context, path string) (Webhook, error)
+	//ListCookies
+	//
+	//  SELECT session, domain, path, name, value, host_only, secure, http_only, expires, updated FROM cookies
+	//  WHERE session = $1
+	//  ORDER BY domain, path, name
+	ListCookies(ctx context.Context, session string) ([]Cookie, error)
+	//ListKnowledge
+	//
+	//  SELECT name, content, created, updated FROM knowledge
+	//  ORDER BY name
+	ListKnowledge(ctx context.Context) ([]Knowledge, error)
+	//ListKnowledgeChunkHashes
+	//
+	//  SELECT DISTINCT name, hash FROM knowledge_chunks
+	ListKnowledgeChunkHashes(ctx context.Context) ([]ListKnowledgeChunkHashesRow, error)
+	//ListMemories
+	//
+	//  SELECT id, content, source_prompt_id, confidence, created, updated FROM memories
+	//  ORDER BY id
+	ListMemories(ctx context.Context) ([]Memory, error)
 	//ListScheduledJobs
 	//
 	//  SELECT name, created, data FROM scheduled_jobs
 	//  ORDER BY created DESC
 	ListScheduledJobs(ctx context.Context) ([]ScheduledJob, error)
+	//ListSecrets
+	//
+	//  SELECT name, value, updated FROM secrets
+	//  ORDER BY name
+	ListSecrets(ctx context.Context) ([]Secret, error)
+	//SearchKnowledgeChunks
+	//
+	//  SELECT name, chunk, content, (1 - (embedding <=> $1::vector))::float8 AS score
+	//  FROM knowledge_chunks
+	//  ORDER BY embedding <=> $1::vector
+	//  LIMIT $2
+	SearchKnowledgeChunks(ctx context.Context, arg SearchKnowledgeChunksParams) ([]SearchKnowledgeChunksRow, error)
+	//UpdateMemory
+	//
+	//  UPDATE memories
+	//  SET content = $2, updated = $3
+	//  WHERE id = $1
+	UpdateMemory(ctx context.Context, arg UpdateMemoryParams) (int64, error)
+	//UpsertCacheEntry
+	//
+	//  INSERT INTO cache_entries (key, value, expires)
+	//  VALUES ($1, $2, $3)
+	//  ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires = EXCLUDED.expires
+	UpsertCacheEntry(ctx context.Context, arg UpsertCacheEntryParams) error
+	//UpsertCookie
+	//
+	//  INSERT INTO cookies (session, domain, path, name, value, host_only, secure, http_only, expires, updated)
+	//  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	//  ON CONFLICT (session, domain, path, name) DO UPDATE SET value     = EXCLUDED.value,
+	//                                                          host_only = EXCLUDED.host_only,
+	//                                                          secure    = EXCLUDED.secure,
+	//                                                          http_only = EXCLUDED.http_only,
+	//                                                          expires   = EXCLUDED.expires,
+	//                                                          updated   = EXCLUDED.updated
+	UpsertCookie(ctx context.Context, arg UpsertCookieParams) error
+	//UpsertKnowledge
+	//
+	//  INSERT INTO knowledge (name, content, created, updated)
+	//  VALUES ($1, $2, $3, $3)
+	//  ON CONFLICT (name) DO UPDATE SET content = EXCLUDED.content, updated = EXCLUDED.updated
+	UpsertKnowledge(ctx context.Context, arg UpsertKnowledgeParams) error
+	//UpsertSecret
+	//
+	//  INSERT INTO secrets (name, value, updated)
+	//  VALUES ($1, $2, $3)
+	//  ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value, updated = EXCLUDED.updated
+	UpsertSecret(ctx context.Context, arg UpsertSecretParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -17,6 +17,106 @@ WHERE name = $1;
 -- name: CountScheduledJobs :one
 SELECT COUNT(*) FROM scheduled_jobs;
 
+-- name: CreateWebhook :exec
+INSERT INTO webhooks (path, secret, prompt, created)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE path = $1;
+
+-- name: UpsertSecret :exec
+INSERT INTO secrets (name, value, updated)
+VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value, updated = EXCLUDED.updated;
+
+-- name: ListSecrets :many
+SELECT * FROM secrets
+ORDER BY name;
+
+-- name: DeleteSecret :exec
+DELETE FROM secrets
+WHERE name = $1;
+
+-- name: ListCookies :many
+SELECT * FROM cookies
+WHERE session = $1
+ORDER BY domain, path, name;
+
+-- name: UpsertCookie :exec
+INSERT INTO cookies (session, domain, path, name, value, host_only, secure, http_only, expires, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (session, domain, path, name) DO UPDATE SET value     = EXCLUDED.value,
+                                                        host_only = EXCLUDED.host_only,
+                                                        secure    = EXCLUDED.secure,
+                                                        http_only = EXCLUDED.http_only,
+                                                        expires   = EXCLUDED.expires,
+                                                        updated   = EXCLUDED.updated;
+
+-- name: DeleteCookie :exec
+DELETE FROM cookies
+WHERE session = $1 AND domain = $2 AND path = $3 AND name = $4;
+
+-- name: GetCacheEntry :one
+SELECT value FROM cache_entries
+WHERE key = $1 AND expires > $2;
+
+-- name: UpsertCacheEntry :exec
+INSERT INTO cache_entries (key, value, expires)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires = EXCLUDED.expires;
+
+-- name: DeleteExpiredCacheEntries :exec
+DELETE FROM cache_entries
+WHERE expires <= $1;
+
+-- name: UpsertKnowledge :exec
+INSERT INTO knowledge (name, content, created, updated)
+VALUES ($1, $2, $3, $3)
+ON CONFLICT (name) DO UPDATE SET content = EXCLUDED.content, updated = EXCLUDED.updated;
+
+-- name: ListKnowledge :many
+SELECT * FROM knowledge
+ORDER BY name;
+
+-- name: DeleteKnowledge :execrows
+DELETE FROM knowledge
+WHERE name = $1;
+
+-- name: ListKnowledgeChunkHashes :many
+SELECT DISTINCT name, hash FROM knowledge_chunks;
+
+-- name: CreateKnowledgeChunk :exec
+INSERT INTO knowledge_chunks (name, chunk, content, hash, embedding)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: DeleteKnowledgeChunks :exec
+DELETE FROM knowledge_chunks
+WHERE name = $1;
+
+-- name: SearchKnowledgeChunks :many
+SELECT name, chunk, content, (1 - (embedding <=> sqlc.arg(embedding)::vector))::float8 AS score
+FROM knowledge_chunks
+ORDER BY embedding <=> sqlc.arg(embedding)::vector
+LIMIT sqlc.arg(max_results);
+
+-- name: CreateMemory :one
+INSERT INTO memories (content, source_prompt_id, confidence, created, updated)
+VALUES ($1, $2, $3, $4, $4) RETURNING id;
+
+-- name: ListMemories :many
+SELECT * FROM memories
+ORDER BY id;
+
+-- name: UpdateMemory :execrows
+UPDATE memories
+SET content = $2, updated = $3
+WHERE id = $1;
+
+-- name: DeleteMemory :execrows
+DELETE FROM memories
+WHERE id = $1;
+
 -- name: GetMigrations :many
 SELECT *
 FROM migration
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"frank/app/dto"
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
 )
 
 const countScheduledJobs = `-- name: CountScheduledJobs :one
@@ -26,6 +28,62 @@ func (q *Queries) CountScheduledJobs(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const createKnowledgeChunk = `-- name: CreateKnowledgeChunk :exec
+INSERT INTO knowledge_chunks (name, chunk, content, hash, embedding)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateKnowledgeChunkParams struct {
+	Name      string
+	Chunk     int32
+	Content   string
+	Hash      string
+	Embedding *pgvector.Vector
+}
+
+// CreateKnowledgeChunk
+//
+//	INSERT INTO knowledge_chunks (name, chunk, content, hash, embedding)
+//	VALUES ($1, $2, $3, $4, $5)
+func (q *Queries) CreateKnowledgeChunk(ctx context.Context, arg CreateKnowledgeChunkParams) error {
+	_, err := q.db.Exec(ctx, createKnowledgeChunk,
+		arg.Name,
+		arg.Chunk,
+		arg.Content,
+		arg.Hash,
+		arg.Embedding,
+	)
+	return err
+}
+
+const createMemory = `-- name: CreateMemory :one
+INSERT INTO memories (content, source_prompt_id, confidence, created, updated)
+VALUES ($1, $2, $3, $4, $4) RETURNING id
+`
+
+type CreateMemoryParams struct {
+	Content        string
+	SourcePromptID uuid.UUID
+	Confidence     float32
+	Created        time.Time
+}
+
+// CreateMemory
+//
+//	INSERT INTO memories (content, source_prompt_id, confidence, created, updated)
+//	VALUES ($1, $2, $3, $4, $4) RETURNING id
+func (q *Queries) CreateMemory(ctx context.Context, arg CreateMemoryParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createMemory,
+		arg.Content,
+		arg.SourcePromptID,
+		arg.Confidence,
+		arg.Created,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const createMigration = `-- name: CreateMigration :one
 INSERT INTO migration (id, applied)
 VALUES ($1, $2) RETURNING id
@@ -67,6 +125,120 @@ func (q *Queries) CreateScheduledJob(ctx context.Context, arg CreateScheduledJob
 	return err
 }
 
+const createWebhook = `-- name: CreateWebhook :exec
+INSERT INTO webhooks (path, secret, prompt, created)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateWebhookParams struct {
+	Path    string
+	Secret  string
+	Prompt  string
+	Created time.Time
+}
+
+// CreateWebhook
+//
+//	INSERT INTO webhooks (path, secret, prompt, created)
+//	VALUES ($1, $2, $3, $4)
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) error {
+	_, err := q.db.Exec(ctx, createWebhook,
+		arg.Path,
+		arg.Secret,
+		arg.Prompt,
+		arg.Created,
+	)
+	return err
+}
+
+const deleteCookie = `-- name: DeleteCookie :exec
+DELETE FROM cookies
+WHERE session = $1 AND domain = $2 AND path = $3 AND name = $4
+`
+
+type DeleteCookieParams struct {
+	Session string
+	Domain  string
+	Path    string
+	Name    string
+}
+
+// DeleteCookie
+//
+//	DELETE FROM cookies
+//	WHERE session = $1 AND domain = $2 AND path = $3 AND name = $4
+func (q *Queries) DeleteCookie(ctx context.Context, arg DeleteCookieParams) error {
+	_, err := q.db.Exec(ctx, deleteCookie,
+		arg.Session,
+		arg.Domain,
+		arg.Path,
+		arg.Name,
+	)
+	return err
+}
+
+const deleteExpiredCacheEntries = `-- name: DeleteExpiredCacheEntries :exec
+DELETE FROM cache_entries
+WHERE expires <= $1
+`
+
+// DeleteExpiredCacheEntries
+//
+//	DELETE FROM cache_entries
+//	WHERE expires <= $1
+func (q *Queries) DeleteExpiredCacheEntries(ctx context.Context, expires time.Time) error {
+	_, err := q.db.Exec(ctx, deleteExpiredCacheEntries, expires)
+	return err
+}
+
+const deleteKnowledge = `-- name: DeleteKnowledge :execrows
+DELETE FROM knowledge
+WHERE name = $1
+`
+
+// DeleteKnowledge
+//
+//	DELETE FROM knowledge
+//	WHERE name = $1
+func (q *Queries) DeleteKnowledge(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteKnowledge, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteKnowledgeChunks = `-- name: DeleteKnowledgeChunks :exec
+DELETE FROM knowledge_chunks
+WHERE name = $1
+`
+
+// DeleteKnowledgeChunks
+//
+//	DELETE FROM knowledge_chunks
+//	WHERE name = $1
+func (q *Queries) DeleteKnowledgeChunks(ctx context.Context, name string) error {
+	_, err := q.db.Exec(ctx, deleteKnowledgeChunks, name)
+	return err
+}
+
+const deleteMemory = `-- name: DeleteMemory :execrows
+DELETE FROM memories
+WHERE id = $1
+`
+
+// DeleteMemory
+//
+//	DELETE FROM memories
+//	WHERE id = $1
+func (q *Queries) DeleteMemory(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteMemory, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteScheduledJob = `-- name: DeleteScheduledJob :exec
 DELETE FROM scheduled_jobs
 WHERE name = $1
@@ -81,6 +253,41 @@ func (q *Queries) DeleteScheduledJob(ctx context.Context, name string) error {
 	return err
 }
 
+const deleteSecret = `-- name: DeleteSecret :exec
+DELETE FROM secrets
+WHERE name = $1
+`
+
+// DeleteSecret
+//
+//	DELETE FROM secrets
+//	WHERE name = $1
+func (q *Queries) DeleteSecret(ctx context.Context, name string) error {
+	_, err := q.db.Exec(ctx, deleteSecret, name)
+	return err
+}
+
+const getCacheEntry = `-- name: GetCacheEntry :one
+SELECT value FROM cache_entries
+WHERE key = $1 AND expires > $2
+`
+
+type GetCacheEntryParams struct {
+	Key     string
+	Expires time.Time
+}
+
+// GetCacheEntry
+//
+//	SELECT value FROM cache_entries
+//	WHERE key = $1 AND expires > $2
+func (q *Queries) GetCacheEntry(ctx context.Context, arg GetCacheEntryParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getCacheEntry, arg.Key, arg.Expires)
+	var value []byte
+	err := row.Scan(&value)
+	return value, err
+}
+
 const getMigrations = `-- name: GetMigrations :many
 SELECT id, applied
 FROM migration
@@ -128,6 +335,171 @@ func (q *Queries) GetScheduledJob(ctx context.Context, name string) (ScheduledJo
 	return i, err
 }
 
+const getWebhook = `-- name: GetWebhook :one
+SELECT path, secret, prompt, created FROM webhooks
+WHERE path = $1
+`
+
+// GetWebhook
+//
+//	SELECT path, secret, prompt, created FROM webhooks
+//	WHERE path = $1
+func (q *Queries) GetWebhook(ctx context.Context, path string) (Webhook, error) {
+	row := q.db.QueryRow(ctx, getWebhook, path)
+	var i Webhook
+	err := row.Scan(
+		&i.Path,
+		&i.Secret,
+		&i.Prompt,
+		&i.Created,
+	)
+	return i, err
+}
+
+const listCookies = `-- name: ListCookies :many
+SELECT session, domain, path, name, value, host_only, secure, http_only, expires, updated FROM cookies
+WHERE session = $1
+ORDER BY domain, path, name
+`
+
+// ListCookies
+//
+//	SELECT session, domain, path, name, value, host_only, secure, http_only, expires, updated FROM cookies
+//	WHERE session = $1
+//	ORDER BY domain, path, name
+func (q *Queries) ListCookies(ctx context.Context, session string) ([]Cookie, error) {
+	rows, err := q.db.Query(ctx, listCookies, session)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Cookie{}
+	for rows.Next() {
+		var i Cookie
+		if err := rows.Scan(
+			&i.Session,
+			&i.Domain,
+			&i.Path,
+			&i.Name,
+			&i.Value,
+			&i.HostOnly,
+			&i.Secure,
+			&i.HttpOnly,
+			&i.Expires,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKnowledge = `-- name: ListKnowledge :many
+SELECT name, content, created, updated FROM knowledge
+ORDER BY name
+`
+
+// ListKnowledge
+//
+//	SELECT name, content, created, updated FROM knowledge
+//	ORDER BY name
+func (q *Queries) ListKnowledge(ctx context.Context) ([]Knowledge, error) {
+	rows, err := q.db.Query(ctx, listKnowledge)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Knowledge{}
+	for rows.Next() {
+		var i Knowledge
+		if err := rows.Scan(
+			&i.Name,
+			&i.Content,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKnowledgeChunkHashes = `-- name: ListKnowledgeChunkHashes :many
+SELECT DISTINCT name, hash FROM knowledge_chunks
+`
+
+type ListKnowledgeChunkHashesRow struct {
+	Name string
+	Hash string
+}
+
+// ListKnowledgeChunkHashes
+//
+//	SELECT DISTINCT name, hash FROM knowledge_chunks
+func (q *Queries) ListKnowledgeChunkHashes(ctx context.Context) ([]ListKnowledgeChunkHashesRow, error) {
+	rows, err := q.db.Query(ctx, listKnowledgeChunkHashes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListKnowledgeChunkHashesRow{}
+	for rows.Next() {
+		var i ListKnowledgeChunkHashesRow
+		if err := rows.Scan(&i.Name, &i.Hash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMemories = `-- name: ListMemories :many
+SELECT id, content, source_prompt_id, confidence, created, updated FROM memories
+ORDER BY id
+`
+
+// ListMemories
+//
+//	SELECT id, content, source_prompt_id, confidence, created, updated FROM memories
+//	ORDER BY id
+func (q *Queries) ListMemories(ctx context.Context) ([]Memory, error) {
+	rows, err := q.db.Query(ctx, listMemories)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Memory{}
+	for rows.Next() {
+		var i Memory
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.SourcePromptID,
+			&i.Confidence,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listScheduledJobs = `-- name: ListScheduledJobs :many
 SELECT name, created, data FROM scheduled_jobs
 ORDER BY created DESC
@@ -156,3 +528,223 @@ func (q *Queries) ListScheduledJobs(ctx context.Context) ([]ScheduledJob, error)
 	}
 	return items, nil
 }
+
+const listSecrets = `-- name: ListSecrets :many
+SELECT name, value, updated FROM secrets
+ORDER BY name
+`
+
+// ListSecrets
+//
+//	SELECT name, value, updated FROM secrets
+//	ORDER BY name
+func (q *Queries) ListSecrets(ctx context.Context) ([]Secret, error) {
+	rows, err := q.db.Query(ctx, listSecrets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Secret{}
+	for rows.Next() {
+		var i Secret
+		if err := rows.Scan(&i.Name, &i.Value, &i.Updated); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchKnowledgeChunks = `-- name: SearchKnowledgeChunks :many
+SELECT name, chunk, content, (1 - (embedding <=> $1::vector))::float8 AS score
+FROM knowledge_chunks
+ORDER BY embedding <=> $1::vector
+LIMIT $2
+`
+
+type SearchKnowledgeChunksParams struct {
+	Embedding  *pgvector.Vector
+	MaxResults int32
+}
+
+type SearchKnowledgeChunksRow struct {
+	Name    string
+	Chunk   int32
+	Content string
+	Score   float64
+}
+
+// SearchKnowledgeChunks
+//
+//	SELECT name, chunk, content, (1 - (embedding <=> $1::vector))::float8 AS score
+//	FROM knowledge_chunks
+//	ORDER BY embedding <=> $1::vector
+//	LIMIT $2
+func (q *Queries) SearchKnowledgeChunks(ctx context.Context, arg SearchKnowledgeChunksParams) ([]SearchKnowledgeChunksRow, error) {
+	rows, err := q.db.Query(ctx, searchKnowledgeChunks, arg.Embedding, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchKnowledgeChunksRow{}
+	for rows.Next() {
+		var i SearchKnowledgeChunksRow
+		if err := rows.Scan(
+			&i.Name,
+			&i.Chunk,
+			&i.Content,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateMemory = `-- name: UpdateMemory :execrows
+UPDATE memories
+SET content = $2, updated = $3
+WHERE id = $1
+`
+
+type UpdateMemoryParams struct {
+	ID      int64
+	Content string
+	Updated time.Time
+}
+
+// UpdateMemory
+//
+//	UPDATE memories
+//	SET content = $2, updated = $3
+//	WHERE id = $1
+func (q *Queries) UpdateMemory(ctx context.Context, arg UpdateMemoryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateMemory, arg.ID, arg.Content, arg.Updated)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertCacheEntry = `-- name: UpsertCacheEntry :exec
+INSERT INTO cache_entries (key, value, expires)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires = EXCLUDED.expires
+`
+
+type UpsertCacheEntryParams struct {
+	Key     string
+	Value   []byte
+	Expires time.Time
+}
+
+// UpsertCacheEntry
+//
+//	INSERT INTO cache_entries (key, value, expires)
+//	VALUES ($1, $2, $3)
+//	ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires = EXCLUDED.expires
+func (q *Queries) UpsertCacheEntry(ctx context.Context, arg UpsertCacheEntryParams) error {
+	_, err := q.db.Exec(ctx, upsertCacheEntry, arg.Key, arg.Value, arg.Expires)
+	return err
+}
+
+const upsertCookie = `-- name: UpsertCookie :exec
+INSERT INTO cookies (session, domain, path, name, value, host_only, secure, http_only, expires, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (session, domain, path, name) DO UPDATE SET value     = EXCLUDED.value,
+                                                        host_only = EXCLUDED.host_only,
+                                                        secure    = EXCLUDED.secure,
+                                                        http_only = EXCLUDED.http_only,
+                                                        expires   = EXCLUDED.expires,
+                                                        updated   = EXCLUDED.updated
+`
+
+type UpsertCookieParams struct {
+	Session  string
+	Domain   string
+	Path     string
+	Name     string
+	Value    []byte
+	HostOnly bool
+	Secure   bool
+	HttpOnly bool
+	Expires  *time.Time
+	Updated  time.Time
+}
+
+// UpsertCookie
+//
+//	INSERT INTO cookies (session, domain, path, name, value, host_only, secure, http_only, expires, updated)
+//	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+//	ON CONFLICT (session, domain, path, name) DO UPDATE SET value     = EXCLUDED.value,
+//	                                                        host_only = EXCLUDED.host_only,
+//	                                                        secure    = EXCLUDED.secure,
+//	                                                        http_only = EXCLUDED.http_only,
+//	                                                        expires   = EXCLUDED.expires,
+//	                                                        updated   = EXCLUDED.updated
+func (q *Queries) UpsertCookie(ctx context.Context, arg UpsertCookieParams) error {
+	_, err := q.db.Exec(ctx, upsertCookie,
+		arg.Session,
+		arg.Domain,
+		arg.Path,
+		arg.Name,
+		arg.Value,
+		arg.HostOnly,
+		arg.Secure,
+		arg.HttpOnly,
+		arg.Expires,
+		arg.Updated,
+	)
+	return err
+}
+
+const upsertKnowledge = `-- name: UpsertKnowledge :exec
+INSERT INTO knowledge (name, content, created, updated)
+VALUES ($1, $2, $3, $3)
+ON CONFLICT (name) DO UPDATE SET content = EXCLUDED.content, updated = EXCLUDED.updated
+`
+
+type UpsertKnowledgeParams struct {
+	Name    string
+	Content string
+	Created time.Time
+}
+
+// UpsertKnowledge
+//
+//	INSERT INTO knowledge (name, content, created, updated)
+//	VALUES ($1, $2, $3, $3)
+//	ON CONFLICT (name) DO UPDATE SET content = EXCLUDED.content, updated = EXCLUDED.updated
+func (q *Queries) UpsertKnowledge(ctx context.Context, arg UpsertKnowledgeParams) error {
+	_, err := q.db.Exec(ctx, upsertKnowledge, arg.Name, arg.Content, arg.Created)
+	return err
+}
+
+const upsertSecret = `-- name: UpsertSecret :exec
+INSERT INTO secrets (name, value, updated)
+VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value, updated = EXCLUDED.updated
+`
+
+type UpsertSecretParams struct {
+	Name    string
+	Value   []byte
+	Updated time.Time
+}
+
+// UpsertSecret
+//
+//	INSERT INTO secrets (name, value, updated)
+//	VALUES ($1, $2, $3)
+//	ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value, updated = EXCLUDED.updated
+func (q *Queries) UpsertSecret(ctx context.Context, arg UpsertSecretParams) error {
+	_, err := q.db.Exec(ctx, upsertSecret, arg.Name, arg.Value, arg.Updated)
+	return err
+}
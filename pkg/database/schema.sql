@@ -10,3 +10,58 @@ CREATE TABLE IF NOT EXISTS migration
     id      VARCHAR(255) PRIMARY KEY,
     applied TIMESTAMP NOT NULL
 );
+
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    path    VARCHAR(255) PRIMARY KEY,
+    secret  VARCHAR(255) NOT NULL,
+    prompt  TEXT         NOT NULL,
+    created TIMESTAMP    NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS secrets
+(
+    name    VARCHAR(255) PRIMARY KEY,
+    value   BYTEA     NOT NULL,
+    updated TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS cookies
+(
+    session   VARCHAR(255) NOT NULL,
+    domain    VARCHAR(255) NOT NULL,
+    path      VARCHAR(255) NOT NULL,
+    name      VARCHAR(255) NOT NULL,
+    value     BYTEA        NOT NULL,
+    host_only BOOLEAN      NOT NULL,
+    secure    BOOLEAN      NOT NULL,
+    http_only BOOLEAN      NOT NULL,
+    expires   TIMESTAMP,
+    updated   TIMESTAMP    NOT NULL,
+    PRIMARY KEY (session, domain, path, name)
+);
+
+CREATE TABLE IF NOT EXISTS cache_entries
+(
+    key     VARCHAR(255) PRIMARY KEY,
+    value   BYTEA     NOT NULL,
+    expires TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS knowledge
+(
+    name    VARCHAR(255) PRIMARY KEY,
+    content TEXT      NOT NULL,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS memories
+(
+    id               BIGSERIAL PRIMARY KEY,
+    content          TEXT      NOT NULL,
+    source_prompt_id UUID      NOT NULL,
+    confidence       REAL      NOT NULL,
+    created          TIMESTAMP NOT NULL,
+    updated          TIMESTAMP NOT NULL
+);
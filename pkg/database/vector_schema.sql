@@ -0,0 +1,12 @@
+-- applied only when knowledge is retrieved by vector similarity, requires the pgvector extension
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE IF NOT EXISTS knowledge_chunks
+(
+    name      VARCHAR(255) NOT NULL,
+    chunk     INTEGER      NOT NULL,
+    content   TEXT         NOT NULL,
+    hash      VARCHAR(64)  NOT NULL,
+    embedding vector       NOT NULL,
+    PRIMARY KEY (name, chunk)
+);
@@ -0,0 +1,236 @@
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"frank/pkg/config"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrDenied is wrapped by every error caused by the policy
+var ErrDenied = errors.New("denied by egress policy")
+
+// the default limit of net/http
+var maxRedirects = 10
+
+// internal ranges, denied unless explicitly allowed
+var defaultDenyCIDRs = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	// NAT64, 6to4 and Teredo addresses embed IPv4 ones and may be routed to internal networks
+	"64:ff9b::/96",
+	"64:ff9b:1::/48",
+	"2001::/32",
+	"2002::/16",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+}
+
+// Policy decides which outbound requests are allowed. URLs are checked before sending and on every redirect,
+// resolved addresses are checked at dial time, so that DNS rebinding can't sneak an internal address in.
+type Policy struct {
+	schemes    []string
+	ports      []int
+	allowHosts []string
+	denyHosts  []string
+	allowCIDRs []netip.Prefix
+	denyCIDRs  []netip.Prefix
+	internal   []netip.Prefix
+
+	transport *http.Transport
+}
+
+func NewPolicy(cfg *config.Config) (*Policy, error) {
+	policy := &Policy{
+		schemes:    cfg.Egress.Schemes,
+		ports:      cfg.Egress.Ports,
+		allowHosts: lowerAll(cfg.Egress.AllowHosts),
+		denyHosts:  lowerAll(cfg.Egress.DenyHosts),
+	}
+
+	var err error
+
+	if policy.allowCIDRs, err = parsePrefixes(cfg.Egress.AllowCIDRs); err != nil {
+		return nil, fmt.Errorf("parse allowCIDRs: %w", err)
+	}
+
+	if policy.denyCIDRs, err = parsePrefixes(cfg.Egress.DenyCIDRs); err != nil {
+		return nil, fmt.Errorf("parse denyCIDRs: %w", err)
+	}
+
+	if policy.internal, err = parsePrefixes(defaultDenyCIDRs); err != nil {
+		return nil, fmt.Errorf("parse default CIDRs: %w", err)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   policy.control,
+	}
+
+	policy.transport = &http.Transport{
+		// a proxy would be dialed instead of the target, which defeats the address check
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return policy, nil
+}
+
+// Client returns an HTTP client which enforces the policy for the request and all redirects
+func (p *Policy) Client(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: p.transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+
+			return p.CheckURL(req.URL)
+		},
+	}
+}
+
+// CheckURL validates scheme, host and port of the target. IP literals are checked against CIDRs right away.
+func (p *Policy) CheckURL(target *url.URL) error {
+	scheme := strings.ToLower(target.Scheme)
+	if !slices.Contains(p.schemes, scheme) {
+		return fmt.Errorf("%w: scheme %q is not allowed, allowed schemes: %s", ErrDenied, scheme, strings.Join(p.schemes, ", "))
+	}
+
+	if err := p.checkPort(portOf(target)); err != nil {
+		return err
+	}
+
+	host := strings.ToLower(target.Hostname())
+
+	if slices.ContainsFunc(p.denyHosts, func(pattern string) bool { return matchHost(pattern, host) }) {
+		return fmt.Errorf("%w: host %s is denied", ErrDenied, host)
+	}
+
+	if len(p.allowHosts) > 0 && !slices.ContainsFunc(p.allowHosts, func(pattern string) bool { return matchHost(pattern, host) }) {
+		return fmt.Errorf("%w: host %s is not in the allowed hosts list", ErrDenied, host)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(addr)
+	}
+
+	return nil
+}
+
+// control is called by the dialer with the resolved address, right before connecting
+func (p *Policy) control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: unexpected address %s", ErrDenied, address)
+	}
+
+	if err = p.checkPort(int(addrPort.Port())); err != nil {
+		return err
+	}
+
+	return p.checkAddr(addrPort.Addr())
+}
+
+func (p *Policy) checkPort(port int) error {
+	if len(p.ports) > 0 && !slices.Contains(p.ports, port) {
+		return fmt.Errorf("%w: port %d is not allowed", ErrDenied, port)
+	}
+
+	return nil
+}
+
+func (p *Policy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+
+	if containsAddr(p.denyCIDRs, addr) {
+		return fmt.Errorf("%w: address %s is in a denied network", ErrDenied, addr)
+	}
+
+	if containsAddr(p.allowCIDRs, addr) {
+		return nil
+	}
+
+	if containsAddr(p.internal, addr) {
+		return fmt.Errorf("%w: address %s belongs to a private or internal network", ErrDenied, addr)
+	}
+
+	return nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	return slices.ContainsFunc(prefixes, func(prefix netip.Prefix) bool { return prefix.Contains(addr) })
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("netip.ParsePrefix %s: %w", cidr, err)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+func portOf(target *url.URL) int {
+	if port, err := strconv.Atoi(target.Port()); err == nil {
+		return port
+	}
+
+	if strings.EqualFold(target.Scheme, "https") {
+		return 443
+	}
+
+	return 80
+}
+
+// matchHost supports exact hosts and *.example.com subdomain wildcards
+func matchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+
+	return host == pattern
+}
+
+func lowerAll(values []string) []string {
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		result = append(result, strings.ToLower(value))
+	}
+
+	return result
+}
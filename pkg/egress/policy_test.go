@@ -0,0 +1,140 @@
+package egress
+
+import (
+	"frank/pkg/config"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPolicy(t *testing.T, modify func(cfg *config.Config)) *Policy {
+	cfg := &config.Config{}
+	cfg.Egress.Schemes = []string{"http", "https"}
+
+	if modify != nil {
+		modify(cfg)
+	}
+
+	policy, err := NewPolicy(cfg)
+	require.NoError(t, err)
+
+	return policy
+}
+
+func TestPolicy_CheckURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.Config)
+		url     string
+		allowed bool
+	}{
+		{name: "public host", url: "https://example.com/path", allowed: true},
+		{name: "public ip", url: "http://93.184.216.34/", allowed: true},
+		{name: "unsupported scheme", url: "ftp://example.com/", allowed: false},
+		{name: "loopback ip", url: "http://127.0.0.1:5432/", allowed: false},
+		{name: "metadata ip", url: "http://169.254.169.254/latest/meta-data", allowed: false},
+		{name: "private ip", url: "http://192.168.1.1/", allowed: false},
+		{name: "ipv6 loopback", url: "http://[::1]/", allowed: false},
+		{name: "ipv4 mapped ipv6 loopback", url: "http://[::ffff:127.0.0.1]/", allowed: false},
+		{name: "nat64 loopback", url: "http://[64:ff9b::7f00:1]/", allowed: false},
+		{name: "nat64 metadata ip", url: "http://[64:ff9b::169.254.169.254]/", allowed: false},
+		{name: "6to4 private ip", url: "http://[2002:c0a8:101::1]/", allowed: false},
+		{name: "public ipv6", url: "http://[2606:2800:220:1:248:1893:25c8:1946]/", allowed: true},
+		{
+			name:    "allowed cidr overrides internal ranges",
+			modify:  func(cfg *config.Config) { cfg.Egress.AllowCIDRs = []string{"10.1.0.0/16"} },
+			url:     "http://10.1.2.3/",
+			allowed: true,
+		},
+		{
+			name: "denied cidr wins over allowed",
+			modify: func(cfg *config.Config) {
+				cfg.Egress.AllowCIDRs = []string{"8.8.0.0/16"}
+				cfg.Egress.DenyCIDRs = []string{"8.8.8.0/24"}
+			},
+			url:     "http://8.8.8.8/",
+			allowed: false,
+		},
+		{
+			name:    "denied host wildcard",
+			modify:  func(cfg *config.Config) { cfg.Egress.DenyHosts = []string{"*.internal.example.com"} },
+			url:     "https://db.internal.example.com/",
+			allowed: false,
+		},
+		{
+			name:    "host not in allow list",
+			modify:  func(cfg *config.Config) { cfg.Egress.AllowHosts = []string{"api.github.com"} },
+			url:     "https://example.com/",
+			allowed: false,
+		},
+		{
+			name:    "host in allow list",
+			modify:  func(cfg *config.Config) { cfg.Egress.AllowHosts = []string{"API.github.com"} },
+			url:     "https://api.github.com/user",
+			allowed: true,
+		},
+		{
+			name:    "port not allowed",
+			modify:  func(cfg *config.Config) { cfg.Egress.Ports = []int{80, 443} },
+			url:     "https://example.com:8443/",
+			allowed: false,
+		},
+		{
+			name:    "default port allowed",
+			modify:  func(cfg *config.Config) { cfg.Egress.Ports = []int{443} },
+			url:     "https://example.com/",
+			allowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+
+			err = newTestPolicy(t, tt.modify).CheckURL(target)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrDenied)
+			}
+		})
+	}
+}
+
+func TestPolicy_Client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// a hostname passes the URL check, the resolved loopback address is caught at dial time
+	hostURL := "http://localhost:" + target.Port()
+
+	t.Run("denied at dial time", func(t *testing.T) {
+		client := newTestPolicy(t, nil).Client(5 * time.Second)
+
+		_, err := client.Get(hostURL)
+		assert.ErrorIs(t, err, ErrDenied)
+	})
+
+	t.Run("allowed by cidr", func(t *testing.T) {
+		client := newTestPolicy(t, func(cfg *config.Config) {
+			cfg.Egress.AllowCIDRs = []string{"127.0.0.0/8", "::1/128"}
+		}).Client(5 * time.Second)
+
+		resp, err := client.Get(hostURL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"frank/pkg/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/samber/do"
+)
+
+// cookieValuesBytea switches cookies created before encryption to the encrypted value column.
+// The plaintext cookies are dropped, sessions just need to log in again.
+type cookieValuesBytea struct{}
+
+func (cookieValuesBytea) Id() string {
+	return "cookie_values_bytea"
+}
+
+func (cookieValuesBytea) Execute(ctx context.Context, _ *do.Injector, tx pgx.Tx, _ *database.Queries) error {
+	var dataType string
+
+	if err := tx.QueryRow(ctx, `
+		SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'cookies' AND column_name = 'value'
+	`).Scan(&dataType); err != nil {
+		return fmt.Errorf("failed to get cookie value type: %w", err)
+	}
+
+	if dataType == "bytea" {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM cookies`); err != nil {
+		return fmt.Errorf("failed to delete plaintext cookies: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `ALTER TABLE cookies ALTER COLUMN value TYPE BYTEA USING convert_to(value, 'UTF8')`); err != nil {
+		return fmt.Errorf("failed to alter cookie value type: %w", err)
+	}
+
+	return nil
+}
@@ -20,7 +20,9 @@ type Migration interface {
 	Execute(ctx context.Context, di *do.Injector, tx pgx.Tx, queries *database.Queries) error
 }
 
-var allMigrations = []Migration{}
+var allMigrations = []Migration{
+	cookieValuesBytea{},
+}
 
 func doExecute(
 	ctx context.Context,
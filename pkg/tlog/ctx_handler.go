@@ -18,7 +18,7 @@ func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
 
 func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &contextHandler{
-		handler: h.handler.WithAttrs(attrs),
+		handler: h.handler.WithAttrs(redactAttrs(attrs)),
 	}
 }
 
@@ -29,6 +29,8 @@ func (h *contextHandler) WithGroup(name string) slog.Handler {
 }
 
 func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r = redactRecord(r)
+
 	if username, ok := ctx.Value(util.UsernameContextKey).(string); ok {
 		r.AddAttrs(slog.String("username", username))
 	}
@@ -37,5 +39,9 @@ func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
 		r.AddAttrs(slog.String("ip", ip))
 	}
 
+	if promptID, ok := ctx.Value(util.PromptIDContextKey).(string); ok {
+		r.AddAttrs(slog.String("prompt_id", promptID))
+	}
+
 	return h.handler.Handle(ctx, r) //nolint: wrapcheck
 }
@@ -0,0 +1,77 @@
+package tlog
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+type Redactor interface {
+	Redact(text string) string
+}
+
+var redactor atomic.Value
+
+// SetRedactor makes every following log record pass through the redactor, e.g. to hide secret values
+func SetRedactor(r Redactor) {
+	redactor.Store(r)
+}
+
+func redact(text string) string {
+	r, ok := redactor.Load().(Redactor)
+	if !ok {
+		return text
+	}
+
+	return r.Redact(text)
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, redact(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, 0, len(group))
+
+		for _, a := range group {
+			redacted = append(redacted, redactAttr(a))
+		}
+
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		text := fmt.Sprint(value.Any())
+
+		// keep the original value unless it actually contains a secret
+		if redactedText := redact(text); redactedText != text {
+			return slog.String(attr.Key, redactedText)
+		}
+
+		return attr
+	default:
+		return attr
+	}
+}
+
+func redactAttrs(attrs []slog.Attr) []slog.Attr {
+	result := make([]slog.Attr, 0, len(attrs))
+
+	for _, attr := range attrs {
+		result = append(result, redactAttr(attr))
+	}
+
+	return result
+}
+
+func redactRecord(r slog.Record) slog.Record {
+	result := slog.NewRecord(r.Time, r.Level, redact(r.Message), r.PC)
+
+	r.Attrs(func(attr slog.Attr) bool {
+		result.AddAttrs(redactAttr(attr))
+		return true
+	})
+
+	return result
+}
@@ -8,3 +8,5 @@ func (c ContextKey) String() string {
 
 var UsernameContextKey ContextKey = "username"
 var IpContextKey ContextKey = "ip"
+var PromptIDContextKey ContextKey = "prompt_id"
+var ChannelContextKey ContextKey = "channel"